@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/titpetric/tp-link-cli/model"
+)
+
+// ListAPN prints the APN profiles
+func (c *SMSCommand) ListAPN(ctx context.Context) error {
+	smsClient, err := c.NewClient()
+	if err != nil {
+		return err
+	}
+	defer c.CloseClient(ctx, smsClient)
+
+	profiles, err := smsClient.APNProfiles(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list APN profiles: %w", err)
+	}
+
+	var rows [][]string
+	for _, p := range profiles {
+		var flags []string
+		if p.Active {
+			flags = append(flags, "active")
+		}
+		if p.Default {
+			flags = append(flags, "built-in")
+		}
+		rows = append(rows, []string{strconv.Itoa(p.ID), p.Name, p.APN, p.AuthType, p.Username, p.PDPType, strings.Join(flags, ", ")})
+	}
+
+	header := []string{"ID", "Name", "APN", "Auth", "Username", "PDP", ""}
+	return c.outputTable(os.Stdout, profiles, header, rows)
+}
+
+// AddAPN adds an APN profile from the options
+func (c *SMSCommand) AddAPN(ctx context.Context) error {
+	if err := c.readAPNPassword(newSecretReader(os.Stdin)); err != nil {
+		return err
+	}
+	profile := c.apnProfile(model.APNProfile{AuthType: "none", PDPType: "ipv4"})
+
+	smsClient, err := c.NewClient()
+	if err != nil {
+		return err
+	}
+	defer c.CloseClient(ctx, smsClient)
+
+	id, err := smsClient.AddAPNProfile(ctx, profile)
+	if err != nil {
+		return fmt.Errorf("failed to add APN profile: %w", err)
+	}
+	fmt.Printf("APN profile %d added\n", id)
+	return nil
+}
+
+// EditAPN changes the options given for an APN profile
+func (c *SMSCommand) EditAPN(ctx context.Context, id int) error {
+	if err := c.readAPNPassword(newSecretReader(os.Stdin)); err != nil {
+		return err
+	}
+
+	smsClient, err := c.NewClient()
+	if err != nil {
+		return err
+	}
+	defer c.CloseClient(ctx, smsClient)
+
+	current, err := smsClient.APNProfile(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get APN profile: %w", err)
+	}
+
+	if err := smsClient.EditAPNProfile(ctx, c.apnProfile(*current)); err != nil {
+		return fmt.Errorf("failed to edit APN profile: %w", err)
+	}
+	fmt.Printf("APN profile %d saved\n", id)
+	return nil
+}
+
+// DeleteAPN deletes an APN profile
+func (c *SMSCommand) DeleteAPN(ctx context.Context, id int) error {
+	smsClient, err := c.NewClient()
+	if err != nil {
+		return err
+	}
+	defer c.CloseClient(ctx, smsClient)
+
+	if err := smsClient.DeleteAPNProfile(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete APN profile: %w", err)
+	}
+	fmt.Printf("APN profile %d deleted\n", id)
+	return nil
+}
+
+// ActivateAPN makes an APN profile the active one
+func (c *SMSCommand) ActivateAPN(ctx context.Context, id int) error {
+	smsClient, err := c.NewClient()
+	if err != nil {
+		return err
+	}
+	defer c.CloseClient(ctx, smsClient)
+
+	if err := smsClient.ActivateAPNProfile(ctx, id); err != nil {
+		return fmt.Errorf("failed to activate APN profile: %w", err)
+	}
+	fmt.Printf("APN profile %d activated\n", id)
+	return nil
+}
+
+// readAPNPassword reads the APN password for --password, unless it is
+// set in TP_LINK_CLI_APN_PASSWORD. On a terminal it is not echoed.
+func (c *SMSCommand) readAPNPassword(secrets *secretReader) error {
+	if !c.ReadPassword || c.Password != "" {
+		return nil
+	}
+	password, err := secrets.read("TP_LINK_CLI_APN_PASSWORD", "APN password")
+	if err != nil {
+		return err
+	}
+	c.Password = password
+	return nil
+}
+
+// apnProfile returns the profile with the options given applied
+func (c *SMSCommand) apnProfile(p model.APNProfile) model.APNProfile {
+	if c.ProfileName != "" {
+		p.Name = c.ProfileName
+	}
+	if c.APN != "" {
+		p.APN = c.APN
+	}
+	if c.AuthType != "" {
+		p.AuthType = c.AuthType
+	}
+	if c.Username != "" {
+		p.Username = c.Username
+	}
+	if c.Password != "" {
+		p.Password = c.Password
+	}
+	if c.PDPType != "" {
+		p.PDPType = c.PDPType
+	}
+	return p
+}
@@ -0,0 +1,183 @@
+// Package archive reads and writes SMS backups as JSON lines.
+//
+// Each line holds one model.SMSMessage. Messages are identified by their
+// host and content hash, so archives from several routers can be merged.
+package archive
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/titpetric/tp-link-cli/model"
+)
+
+// Key returns the deduplication key of a message.
+func Key(msg model.SMSMessage) string {
+	hash := msg.Hash
+	if hash == "" {
+		hash = msg.ContentHash()
+	}
+	return msg.Host + "/" + hash
+}
+
+// Read loads the messages from an archive. A missing archive is empty.
+func Read(path string) ([]model.SMSMessage, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var result []model.SMSMessage
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var msg model.SMSMessage
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		if msg.Hash == "" {
+			msg.Hash = msg.ContentHash()
+		}
+		result = append(result, msg)
+	}
+
+	return result, scanner.Err()
+}
+
+// Write merges the messages into the archive, and rewrites it
+// deduplicated and sorted. Messages already in the archive are kept, as
+// they may be deleted from the router or come from another router. It
+// returns the number of messages in the archive.
+func Write(path string, messages []model.SMSMessage) (int, error) {
+	existing, err := Read(path)
+	if err != nil {
+		return 0, err
+	}
+
+	// The new messages come first, so they replace the archived ones
+	messages = Dedupe(append(append([]model.SMSMessage{}, messages...), existing...))
+	Sort(messages)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return 0, err
+	}
+
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := encode(f, messages); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return 0, err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return 0, err
+	}
+
+	return len(messages), os.Rename(tmp, path)
+}
+
+// Append adds the messages not already in the archive, in sorted order.
+// It returns the number of messages added.
+func Append(path string, messages []model.SMSMessage) (int, error) {
+	existing, err := Read(path)
+	if err != nil {
+		return 0, err
+	}
+
+	seen := map[string]bool{}
+	for _, msg := range existing {
+		seen[Key(msg)] = true
+	}
+
+	var added []model.SMSMessage
+	for _, msg := range Dedupe(messages) {
+		if !seen[Key(msg)] {
+			added = append(added, msg)
+		}
+	}
+	if len(added) == 0 {
+		return 0, nil
+	}
+	Sort(added)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return 0, err
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return 0, err
+	}
+	if err := encode(f, added); err != nil {
+		f.Close()
+		return 0, err
+	}
+	return len(added), f.Close()
+}
+
+// Dedupe returns the messages with duplicates removed, keeping the
+// first occurrence of each.
+func Dedupe(messages []model.SMSMessage) []model.SMSMessage {
+	seen := map[string]bool{}
+	result := make([]model.SMSMessage, 0, len(messages))
+	for _, msg := range messages {
+		if msg.Hash == "" {
+			msg.Hash = msg.ContentHash()
+		}
+		key := Key(msg)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, msg)
+	}
+	return result
+}
+
+// Sort orders messages oldest first, with ties broken by host, folder
+// and hash, so an archive is written the same way every time.
+func Sort(messages []model.SMSMessage) {
+	sort.SliceStable(messages, func(i, j int) bool {
+		a, b := messages[i], messages[j]
+		if !a.Time().Equal(b.Time()) {
+			return a.Time().Before(b.Time())
+		}
+		if a.Host != b.Host {
+			return a.Host < b.Host
+		}
+		if a.Folder != b.Folder {
+			return a.Folder < b.Folder
+		}
+		return a.Hash < b.Hash
+	})
+}
+
+func encode(f *os.File, messages []model.SMSMessage) error {
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	for _, msg := range messages {
+		if err := enc.Encode(msg); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
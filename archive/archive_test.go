@@ -0,0 +1,123 @@
+package archive
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/titpetric/tp-link-cli/model"
+)
+
+func message(host, folder, content string, t time.Time) model.SMSMessage {
+	msg := model.SMSMessage{
+		From:     "+38640111222",
+		Content:  content,
+		RecvTime: t,
+		Folder:   folder,
+		Host:     host,
+	}
+	msg.Hash = msg.ContentHash()
+	return msg
+}
+
+func TestWriteDedupeAndSort(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "archive.jsonl")
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	newer := message("192.168.1.1", "inbox", "newer", now)
+	older := message("192.168.1.1", "inbox", "older", now.Add(-time.Hour))
+
+	n, err := Write(path, []model.SMSMessage{newer, older, newer})
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	got, err := Read(path)
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+	assert.Equal(t, "older", got[0].Content)
+	assert.Equal(t, "newer", got[1].Content)
+
+	first, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	_, err = Write(path, []model.SMSMessage{older, newer})
+	require.NoError(t, err)
+
+	second, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, string(first), string(second))
+}
+
+func TestWriteKeepsArchived(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "archive.jsonl")
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	deleted := message("192.168.1.1", "inbox", "deleted", now.Add(-time.Hour))
+	other := message("192.168.8.1", "inbox", "other router", now.Add(-time.Minute))
+	current := message("192.168.1.1", "inbox", "current", now)
+
+	_, err := Write(path, []model.SMSMessage{deleted, other})
+	require.NoError(t, err)
+
+	n, err := Write(path, []model.SMSMessage{current})
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+
+	got, err := Read(path)
+	require.NoError(t, err)
+	require.Len(t, got, 3)
+	assert.Equal(t, "deleted", got[0].Content)
+	assert.Equal(t, "other router", got[1].Content)
+	assert.Equal(t, "current", got[2].Content)
+}
+
+func TestAppendIncremental(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "backup", "archive.jsonl")
+	now := time.Now().UTC().Truncate(time.Second)
+
+	a := message("192.168.1.1", "inbox", "a", now.Add(-time.Minute))
+	b := message("192.168.1.1", "inbox", "b", now)
+
+	n, err := Append(path, []model.SMSMessage{a})
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	// The router index changes as messages are deleted, the key does not
+	a.Index = 5
+	n, err = Append(path, []model.SMSMessage{a, b})
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	n, err = Append(path, []model.SMSMessage{a, b})
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+
+	got, err := Read(path)
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+}
+
+func TestAppendMergesHosts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "archive.jsonl")
+	now := time.Now().UTC().Truncate(time.Second)
+
+	first := message("192.168.1.1", "inbox", "broadcast", now)
+	second := message("192.168.8.1", "inbox", "broadcast", now)
+	assert.Equal(t, first.Hash, second.Hash)
+
+	_, err := Append(path, []model.SMSMessage{first})
+	require.NoError(t, err)
+	n, err := Append(path, []model.SMSMessage{second})
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+}
+
+func TestReadMissing(t *testing.T) {
+	got, err := Read(filepath.Join(t.TempDir(), "missing.jsonl"))
+	require.NoError(t, err)
+	assert.Empty(t, got)
+}
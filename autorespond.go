@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/titpetric/tp-link-cli/autorespond"
+)
+
+// AutoRespond applies the rules file to the inbox. With --interval, the
+// rules are applied repeatedly over one session until interrupted.
+func (c *SMSCommand) AutoRespond(ctx context.Context) error {
+	if c.Rules == "" {
+		return fmt.Errorf("autorespond requires --rules=<file>")
+	}
+
+	rules, err := autorespond.LoadRules(c.Rules)
+	if err != nil {
+		return fmt.Errorf("failed to load rules: %w", err)
+	}
+
+	smsClient, err := c.NewClient()
+	if err != nil {
+		return err
+	}
+	defer c.CloseClient(context.Background(), smsClient)
+
+	engine := &autorespond.Engine{
+		Client: smsClient,
+		Rules:  rules,
+		DryRun: c.DryRun,
+	}
+
+	if c.Interval == 0 {
+		return c.applyRules(ctx, engine)
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	ticker := time.NewTicker(c.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := c.applyRules(ctx, engine); err != nil && ctx.Err() == nil {
+			fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (c *SMSCommand) applyRules(ctx context.Context, engine *autorespond.Engine) error {
+	results, err := engine.Run(ctx)
+	for _, result := range results {
+		if c.DryRun && !result.Skipped {
+			fmt.Printf("would %s\n", result)
+			continue
+		}
+		fmt.Println(result)
+	}
+	return err
+}
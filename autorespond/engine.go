@@ -0,0 +1,152 @@
+// Package autorespond runs rules against the router inbox, replying to,
+// forwarding or deleting matching messages.
+//
+// Replies and forwards are de-duplicated against the sent folder. A send
+// is skipped if the sent folder already holds the same text to the same
+// number, sent no earlier than the message was received. Each message is
+// so answered exactly once, however often the rules run.
+package autorespond
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/titpetric/tp-link-cli/archive"
+	"github.com/titpetric/tp-link-cli/client"
+	"github.com/titpetric/tp-link-cli/model"
+)
+
+// Result records an action taken, or skipped, for a message.
+type Result struct {
+	Rule    string
+	Message model.SMSMessage
+	Action  Action
+	// Skipped is set when the sent folder shows the action was done.
+	Skipped bool
+}
+
+// String describes the result.
+func (r Result) String() string {
+	status := "done"
+	if r.Skipped {
+		status = "already done"
+	}
+	return fmt.Sprintf("%s: message %d from %s: %s (%s)", r.Rule, r.Message.Index, r.Message.From, r.Action, status)
+}
+
+// Engine applies rules to the inbox of a router.
+type Engine struct {
+	Client *client.SMSClient
+	Rules  *Rules
+
+	// DryRun reports the actions without sending or deleting.
+	DryRun bool
+	// Now returns the current time, used for max_age.
+	Now func() time.Time
+}
+
+// Run applies the rules to every inbox message, oldest first. Matching
+// rules run in order, a delete action ends the processing of a message.
+func (e *Engine) Run(ctx context.Context) ([]Result, error) {
+	now := time.Now
+	if e.Now != nil {
+		now = e.Now
+	}
+
+	inbox, err := e.Client.ListAll(ctx, "inbox")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list inbox: %w", err)
+	}
+	sent, err := e.Client.ListAll(ctx, "sent")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sent: %w", err)
+	}
+
+	messages := inbox.Data
+	outbox := sent.Data
+
+	// Oldest first, so a reply also covers older messages of the rule
+	archive.Sort(messages)
+
+	var results []Result
+	for _, msg := range messages {
+	message:
+		for _, rule := range e.Rules.Rules {
+			if !rule.Matches(msg, now()) {
+				continue
+			}
+
+			for _, action := range rule.Actions {
+				result := Result{
+					Rule:    rule.Name,
+					Message: msg,
+					Action:  action,
+				}
+
+				if action.Delete {
+					if !e.DryRun {
+						if err := e.delete(ctx, msg); err != nil {
+							return results, err
+						}
+					}
+					results = append(results, result)
+					break message
+				}
+
+				to, text := action.Outgoing(msg)
+				if answered(outbox, to, text, msg.Time()) {
+					result.Skipped = true
+					results = append(results, result)
+					continue
+				}
+
+				if !e.DryRun {
+					if err := e.send(ctx, to, text); err != nil {
+						return results, err
+					}
+				}
+				outbox = append(outbox, model.SMSMessage{
+					To:       to,
+					Content:  text,
+					SentTime: now(),
+				})
+				results = append(results, result)
+			}
+		}
+	}
+	return results, nil
+}
+
+func (e *Engine) send(ctx context.Context, to, text string) error {
+	resp, err := e.Client.Send(ctx, to, text)
+	if err != nil {
+		return fmt.Errorf("failed to send SMS: %w", err)
+	}
+	if resp.Error != 0 {
+		return fmt.Errorf("router returned error code: %d", resp.Error)
+	}
+	return nil
+}
+
+func (e *Engine) delete(ctx context.Context, msg model.SMSMessage) error {
+	resp, err := e.Client.DeleteByIndex(ctx, "inbox", msg.Index)
+	if err != nil {
+		return fmt.Errorf("failed to delete SMS: %w", err)
+	}
+	if resp.Error != 0 {
+		return fmt.Errorf("router returned error code: %d", resp.Error)
+	}
+	return nil
+}
+
+// answered reports whether the outbox holds the text sent to the
+// number no earlier than the message was received.
+func answered(outbox []model.SMSMessage, to, text string, received time.Time) bool {
+	for _, sent := range outbox {
+		if sent.To == to && sent.Content == text && !sent.SentTime.Before(received) {
+			return true
+		}
+	}
+	return false
+}
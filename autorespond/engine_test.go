@@ -0,0 +1,135 @@
+package autorespond
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/titpetric/tp-link-cli/client"
+	"github.com/titpetric/tp-link-cli/fakerouter"
+)
+
+const brzinaRules = `
+rules:
+  - name: brzina
+    from: "13909"
+    match: BRZINA
+    actions:
+      - reply: brzina
+      - forward: "0038612345678"
+        content: Quota router reset after 200G limit.
+`
+
+func newTestEngine(t *testing.T, router *fakerouter.Router, data string) *Engine {
+	t.Helper()
+
+	c, err := client.NewSMSClient(&client.Options{
+		Auth: "admin:secret",
+		Host: router.URL(),
+	})
+	require.NoError(t, err)
+
+	rules, err := ParseRules([]byte(data))
+	require.NoError(t, err)
+
+	return &Engine{
+		Client: c,
+		Rules:  rules,
+	}
+}
+
+func TestEngineAnswersOnce(t *testing.T) {
+	router := fakerouter.New("admin", "secret")
+	defer router.Close()
+
+	router.Receive("13909", "Porabili ste 200G. Odgovorite BRZINA.", time.Now().Add(-2*time.Hour))
+	router.Receive("13909", "Porabili ste 200G. Odgovorite BRZINA.", time.Now().Add(-time.Hour))
+	router.Receive("+38640111222", "BRZINA", time.Now().Add(-time.Hour))
+
+	engine := newTestEngine(t, router, brzinaRules)
+	ctx := context.Background()
+
+	results, err := engine.Run(ctx)
+	require.NoError(t, err)
+
+	sent := map[string]string{}
+	for _, msg := range router.Sent() {
+		sent[msg.To] = msg.Content
+	}
+	assert.Equal(t, map[string]string{
+		"13909":         "brzina",
+		"0038612345678": "Quota router reset after 200G limit.",
+	}, sent)
+
+	// The reply to the first challenge also covers the second one
+	require.Len(t, results, 4)
+	assert.False(t, results[0].Skipped)
+	assert.False(t, results[1].Skipped)
+	assert.True(t, results[2].Skipped)
+	assert.True(t, results[3].Skipped)
+
+	// Running again answers nothing, based on the sent folder
+	results, err = engine.Run(ctx)
+	require.NoError(t, err)
+	assert.Len(t, router.Sent(), 2)
+	for _, result := range results {
+		assert.True(t, result.Skipped)
+	}
+
+	// A challenge arriving after the reply is answered again
+	router.Receive("13909", "Odgovorite BRZINA.", time.Now().Add(time.Minute))
+	_, err = engine.Run(ctx)
+	require.NoError(t, err)
+	assert.Len(t, router.Sent(), 4)
+}
+
+func TestEngineDryRun(t *testing.T) {
+	router := fakerouter.New("admin", "secret")
+	defer router.Close()
+
+	router.Receive("13909", "BRZINA", time.Now())
+
+	engine := newTestEngine(t, router, brzinaRules)
+	engine.DryRun = true
+
+	results, err := engine.Run(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, results, 2)
+	assert.Empty(t, router.Sent())
+}
+
+func TestEngineForwardAndDelete(t *testing.T) {
+	router := fakerouter.New("admin", "secret")
+	defer router.Close()
+
+	router.Receive("+38640111222", "hello", time.Now().Add(-time.Minute))
+	router.Receive("+38640111222", "old", time.Now().Add(-48*time.Hour))
+
+	engine := newTestEngine(t, router, `
+rules:
+  - name: forward
+    max_age: 24h
+    actions:
+      - forward: "031"
+      - delete: true
+      - reply: never sent
+`)
+
+	results, err := engine.Run(context.Background())
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, "forward to 031", results[0].Action.String())
+	assert.Equal(t, "delete", results[1].Action.String())
+
+	sent := router.Sent()
+	require.Len(t, sent, 1)
+	assert.Equal(t, "031", sent[0].To)
+	assert.Equal(t, "+38640111222: hello", sent[0].Content)
+
+	inbox := router.Inbox()
+	require.Len(t, inbox, 1)
+	assert.Equal(t, "old", inbox[0].Content)
+}
@@ -0,0 +1,152 @@
+package autorespond
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/titpetric/tp-link-cli/model"
+)
+
+// Rules is the rules file, as read by LoadRules.
+type Rules struct {
+	Rules []*Rule `yaml:"rules"`
+}
+
+// Rule matches inbox messages and lists the actions taken for them.
+type Rule struct {
+	Name string `yaml:"name"`
+
+	// From matches the sender exactly, empty matches any sender.
+	From string `yaml:"from"`
+	// Match is a regular expression matched against the content,
+	// empty matches any content.
+	Match string `yaml:"match"`
+	// MaxAge skips messages received longer ago, zero disables it.
+	MaxAge time.Duration `yaml:"max_age"`
+
+	Actions []Action `yaml:"actions"`
+
+	match *regexp.Regexp
+}
+
+// Action is one of reply, forward or delete.
+type Action struct {
+	// Reply sends the text to the sender.
+	Reply string `yaml:"reply"`
+	// Forward sends Content to the number. Without Content, the
+	// message is forwarded as "<sender>: <content>".
+	Forward string `yaml:"forward"`
+	Content string `yaml:"content"`
+	// Delete removes the message from the inbox.
+	Delete bool `yaml:"delete"`
+}
+
+// LoadRules reads and validates a YAML rules file.
+func LoadRules(path string) (*Rules, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseRules(data)
+}
+
+// ParseRules parses and validates YAML rules.
+func ParseRules(data []byte) (*Rules, error) {
+	rules := &Rules{}
+	if err := yaml.Unmarshal(data, rules); err != nil {
+		return nil, fmt.Errorf("failed to parse rules: %w", err)
+	}
+	if len(rules.Rules) == 0 {
+		return nil, errors.New("no rules defined")
+	}
+
+	for i, rule := range rules.Rules {
+		if rule.Name == "" {
+			rule.Name = fmt.Sprintf("rule %d", i+1)
+		}
+		if err := rule.compile(); err != nil {
+			return nil, fmt.Errorf("%s: %w", rule.Name, err)
+		}
+	}
+	return rules, nil
+}
+
+func (r *Rule) compile() error {
+	if r.Match != "" {
+		match, err := regexp.Compile(r.Match)
+		if err != nil {
+			return fmt.Errorf("invalid match: %w", err)
+		}
+		r.match = match
+	}
+	if r.MaxAge < 0 {
+		return errors.New("max_age must not be negative")
+	}
+	if len(r.Actions) == 0 {
+		return errors.New("no actions defined")
+	}
+
+	for i, action := range r.Actions {
+		set := 0
+		if action.Reply != "" {
+			set++
+		}
+		if action.Forward != "" {
+			set++
+		}
+		if action.Delete {
+			set++
+		}
+		if set != 1 {
+			return fmt.Errorf("action %d: exactly one of reply, forward or delete is required", i+1)
+		}
+		if action.Content != "" && action.Forward == "" {
+			return fmt.Errorf("action %d: content is only valid with forward", i+1)
+		}
+	}
+	return nil
+}
+
+// Matches reports whether the inbox message matches the rule at now.
+func (r *Rule) Matches(msg model.SMSMessage, now time.Time) bool {
+	if r.From != "" && msg.From != r.From {
+		return false
+	}
+	if r.match != nil && !r.match.MatchString(msg.Content) {
+		return false
+	}
+	if r.MaxAge > 0 && now.Sub(msg.Time()) > r.MaxAge {
+		return false
+	}
+	return true
+}
+
+// Outgoing returns the number and text an action sends for a message.
+// Delete actions send nothing, and return empty strings.
+func (a Action) Outgoing(msg model.SMSMessage) (string, string) {
+	switch {
+	case a.Reply != "":
+		return msg.From, a.Reply
+	case a.Forward != "" && a.Content != "":
+		return a.Forward, a.Content
+	case a.Forward != "":
+		return a.Forward, msg.From + ": " + msg.Content
+	}
+	return "", ""
+}
+
+// String describes the action for a message.
+func (a Action) String() string {
+	switch {
+	case a.Reply != "":
+		return fmt.Sprintf("reply %q", a.Reply)
+	case a.Forward != "":
+		return "forward to " + a.Forward
+	}
+	return "delete"
+}
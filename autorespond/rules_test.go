@@ -0,0 +1,105 @@
+package autorespond
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/titpetric/tp-link-cli/model"
+)
+
+func TestParseRules(t *testing.T) {
+	rules, err := ParseRules([]byte(`
+rules:
+  - name: brzina
+    from: "13909"
+    match: "(?i)brzina"
+    max_age: 24h
+    actions:
+      - reply: brzina
+      - forward: "0038612345678"
+        content: Quota router reset after 200G limit.
+  - actions:
+      - delete: true
+`))
+	require.NoError(t, err)
+	require.Len(t, rules.Rules, 2)
+
+	rule := rules.Rules[0]
+	assert.Equal(t, "brzina", rule.Name)
+	assert.Equal(t, 24*time.Hour, rule.MaxAge)
+	require.Len(t, rule.Actions, 2)
+	assert.Equal(t, "brzina", rule.Actions[0].Reply)
+	assert.Equal(t, "0038612345678", rule.Actions[1].Forward)
+
+	assert.Equal(t, "rule 2", rules.Rules[1].Name)
+	assert.True(t, rules.Rules[1].Actions[0].Delete)
+}
+
+func TestParseRulesInvalid(t *testing.T) {
+	tests := map[string]string{
+		"empty":          `rules: []`,
+		"no actions":     "rules:\n  - name: x\n",
+		"bad regexp":     "rules:\n  - match: \"(\"\n    actions:\n      - delete: true\n",
+		"two actions":    "rules:\n  - actions:\n      - reply: a\n        delete: true\n",
+		"no action":      "rules:\n  - actions:\n      - content: a\n",
+		"stray content":  "rules:\n  - actions:\n      - reply: a\n        content: b\n",
+		"bad max_age":    "rules:\n  - max_age: soon\n    actions:\n      - delete: true\n",
+		"negative age":   "rules:\n  - max_age: -1h\n    actions:\n      - delete: true\n",
+		"not a rule set": `- reply: a`,
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseRules([]byte(data))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestRuleMatches(t *testing.T) {
+	rules, err := ParseRules([]byte(`
+rules:
+  - from: "13909"
+    match: BRZINA
+    max_age: 1h
+    actions:
+      - reply: brzina
+`))
+	require.NoError(t, err)
+	rule := rules.Rules[0]
+
+	now := time.Now()
+	msg := model.SMSMessage{From: "13909", Content: "Poraba BRZINA 200G", RecvTime: now.Add(-time.Minute)}
+	assert.True(t, rule.Matches(msg, now))
+
+	other := msg
+	other.From = "13910"
+	assert.False(t, rule.Matches(other, now))
+
+	other = msg
+	other.Content = "hello"
+	assert.False(t, rule.Matches(other, now))
+
+	other = msg
+	other.RecvTime = now.Add(-2 * time.Hour)
+	assert.False(t, rule.Matches(other, now))
+}
+
+func TestActionOutgoing(t *testing.T) {
+	msg := model.SMSMessage{From: "13909", Content: "BRZINA"}
+
+	to, text := Action{Reply: "brzina"}.Outgoing(msg)
+	assert.Equal(t, "13909", to)
+	assert.Equal(t, "brzina", text)
+
+	to, text = Action{Forward: "031"}.Outgoing(msg)
+	assert.Equal(t, "031", to)
+	assert.Equal(t, "13909: BRZINA", text)
+
+	to, text = Action{Forward: "031", Content: "reset"}.Outgoing(msg)
+	assert.Equal(t, "031", to)
+	assert.Equal(t, "reset", text)
+}
@@ -2,23 +2,71 @@ package main
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/titpetric/tp-link-cli/archive"
 	"github.com/titpetric/tp-link-cli/client"
 	"github.com/titpetric/tp-link-cli/model"
-
-	"github.com/olekukonko/tablewriter"
 )
 
 // SMSCommand handles SMS operations.
 type SMSCommand struct {
-	Auth   string
-	Host   string
-	JSON   bool
-	Folder string
+	Auth         string
+	Host         string
+	JSON         bool
+	Folder       string
+	SessionCache bool
+	Page         int
+	All          bool
+	Out          string
+	Incremental  bool
+	Format       string
+	Template     string
+	Listen       string
+	Token        string
+	Interval     time.Duration
+	State        string
+	Exec         string
+	Rules        string
+	DryRun       bool
+	URL          string
+	Secret       string
+	DeadLetter   string
+	AllowFrom    []string
+	RateLimit    int
+	RateWindow   time.Duration
+	SMTP         string
+	SMTPAuth     string
+	MailFrom     string
+	MailTo       []string
+	Delete       bool
+	Broker       string
+	MQTTAuth     string
+	Topic        string
+	Method       string
+	Controller   string
+	Attrs        []string
+	Stack        string
+	Keep         int
+	Alert        string
+	BillingDay   string
+	Wait         bool
+	Timeout      time.Duration
+	ProfileName  string
+	APN          string
+	AuthType     string
+	Username     string
+	Password     string
+	ReadPassword bool
+	PDPType      string
+	Timezone     string
+	Location     *time.Location
 }
 
 // NewSMSCommand will return the environment-filled *SMSCommand.
@@ -34,17 +82,49 @@ func NewSMSCommand() *SMSCommand {
 		host = "192.168.1.1"
 	}
 
+	sessionCache := os.Getenv("TP_LINK_CLI_SESSION_CACHE")
+
 	return &SMSCommand{
-		Auth: auth,
-		Host: host,
+		Auth:         auth,
+		Host:         host,
+		SessionCache: sessionCache != "" && sessionCache != "0",
+		Token:        os.Getenv("TP_LINK_CLI_TOKEN"),
+		Secret:       os.Getenv("TP_LINK_CLI_WEBHOOK_SECRET"),
+		SMTPAuth:     os.Getenv("TP_LINK_CLI_SMTP_AUTH"),
+		MQTTAuth:     os.Getenv("TP_LINK_CLI_MQTT_AUTH"),
+		Password:     os.Getenv("TP_LINK_CLI_APN_PASSWORD"),
+		Timezone:     os.Getenv("TP_LINK_CLI_TIMEZONE"),
 	}
 }
 
 func (c *SMSCommand) ClientOptions() *client.Options {
-	return &client.Options{
-		Auth: c.Auth,
-		Host: c.Host,
+	opts := &client.Options{
+		Auth:     c.Auth,
+		Host:     c.Host,
+		Location: c.Location,
+	}
+	if c.SessionCache {
+		opts.SessionCache = client.NewSessionCache("")
+	}
+	return opts
+}
+
+// NewClient creates a router client from the command options.
+func (c *SMSCommand) NewClient() (*client.SMSClient, error) {
+	smsClient, err := client.NewSMSClient(c.ClientOptions())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client: %w", err)
+	}
+	return smsClient, nil
+}
+
+// CloseClient logs out of the router, so the web UI remains usable.
+// When the session cache is in use, the session is kept for reuse.
+func (c *SMSCommand) CloseClient(ctx context.Context, smsClient *client.SMSClient) {
+	if c.SessionCache || smsClient.TokenID == "" {
+		return
 	}
+	smsClient.Logout(ctx)
 }
 
 // ParseArgs parses command-line arguments
@@ -62,6 +142,122 @@ func ParseArgs(args []string) (*SMSCommand, string, error) {
 		arg := args[i]
 		if arg == "--json" {
 			cmd.JSON = true
+		} else if arg == "--session-cache" {
+			cmd.SessionCache = true
+		} else if arg == "--all" {
+			cmd.All = true
+		} else if arg == "--incremental" {
+			cmd.Incremental = true
+		} else if arg == "--dry-run" {
+			cmd.DryRun = true
+		} else if arg == "--delete" {
+			cmd.Delete = true
+		} else if arg == "--wait" {
+			cmd.Wait = true
+		} else if len(arg) > 9 && arg[:9] == "--format=" {
+			cmd.Format = arg[9:]
+			if _, err := GetFormatter(cmd.Format); err != nil {
+				return nil, "", err
+			}
+		} else if len(arg) > 11 && arg[:11] == "--template=" {
+			cmd.Template = arg[11:]
+			if _, err := parseTemplate(cmd.Template); err != nil {
+				return nil, "", err
+			}
+		} else if len(arg) > 11 && arg[:11] == "--interval=" {
+			interval, err := time.ParseDuration(arg[11:])
+			if err != nil || interval <= 0 {
+				return nil, "", fmt.Errorf("invalid interval: %s", arg[11:])
+			}
+			cmd.Interval = interval
+		} else if len(arg) > 10 && arg[:10] == "--timeout=" {
+			timeout, err := time.ParseDuration(arg[10:])
+			if err != nil || timeout <= 0 {
+				return nil, "", fmt.Errorf("invalid timeout: %s", arg[10:])
+			}
+			cmd.Timeout = timeout
+		} else if len(arg) > 11 && arg[:11] == "--timezone=" {
+			cmd.Timezone = arg[11:]
+		} else if len(arg) > 8 && arg[:8] == "--state=" {
+			cmd.State = arg[8:]
+		} else if len(arg) > 6 && arg[:6] == "--url=" {
+			cmd.URL = arg[6:]
+		} else if len(arg) > 9 && arg[:9] == "--secret=" {
+			cmd.Secret = arg[9:]
+		} else if len(arg) > 14 && arg[:14] == "--dead-letter=" {
+			cmd.DeadLetter = arg[14:]
+		} else if len(arg) > 13 && arg[:13] == "--allow-from=" {
+			cmd.AllowFrom = strings.Split(arg[13:], ",")
+		} else if len(arg) > 13 && arg[:13] == "--rate-limit=" {
+			limit, window, err := parseRateLimit(arg[13:])
+			if err != nil {
+				return nil, "", err
+			}
+			cmd.RateLimit, cmd.RateWindow = limit, window
+		} else if len(arg) > 7 && arg[:7] == "--smtp=" {
+			cmd.SMTP = arg[7:]
+		} else if len(arg) > 12 && arg[:12] == "--smtp-auth=" {
+			cmd.SMTPAuth = arg[12:]
+		} else if len(arg) > 12 && arg[:12] == "--mail-from=" {
+			cmd.MailFrom = arg[12:]
+		} else if len(arg) > 10 && arg[:10] == "--mail-to=" {
+			cmd.MailTo = strings.Split(arg[10:], ",")
+		} else if len(arg) > 9 && arg[:9] == "--broker=" {
+			cmd.Broker = arg[9:]
+		} else if len(arg) > 12 && arg[:12] == "--mqtt-auth=" {
+			cmd.MQTTAuth = arg[12:]
+		} else if len(arg) > 8 && arg[:8] == "--topic=" {
+			cmd.Topic = arg[8:]
+		} else if len(arg) > 9 && arg[:9] == "--method=" {
+			cmd.Method = arg[9:]
+		} else if len(arg) > 13 && arg[:13] == "--controller=" {
+			cmd.Controller = arg[13:]
+		} else if len(arg) > 8 && arg[:8] == "--attrs=" {
+			cmd.Attrs = strings.Split(arg[8:], ",")
+		} else if len(arg) > 8 && arg[:8] == "--stack=" {
+			cmd.Stack = arg[8:]
+		} else if len(arg) > 7 && arg[:7] == "--keep=" {
+			keep, err := strconv.Atoi(arg[7:])
+			if err != nil || keep < 1 {
+				return nil, "", fmt.Errorf("invalid keep: %s", arg[7:])
+			}
+			cmd.Keep = keep
+		} else if len(arg) > 8 && arg[:8] == "--alert=" {
+			cmd.Alert = arg[8:]
+		} else if len(arg) > 14 && arg[:14] == "--billing-day=" {
+			cmd.BillingDay = arg[14:]
+		} else if len(arg) > 7 && arg[:7] == "--name=" {
+			cmd.ProfileName = arg[7:]
+		} else if len(arg) > 6 && arg[:6] == "--apn=" {
+			cmd.APN = arg[6:]
+		} else if len(arg) > 12 && arg[:12] == "--auth-type=" {
+			cmd.AuthType = arg[12:]
+		} else if len(arg) > 11 && arg[:11] == "--username=" {
+			cmd.Username = arg[11:]
+		} else if arg == "--password" {
+			cmd.ReadPassword = true
+		} else if strings.HasPrefix(arg, "--password=") {
+			return nil, "", fmt.Errorf("password on the command line is visible to other users, pass --password to read it from stdin, or set TP_LINK_CLI_APN_PASSWORD")
+		} else if len(arg) > 11 && arg[:11] == "--pdp-type=" {
+			cmd.PDPType = arg[11:]
+		} else if strings.HasPrefix(arg, "--pin=") || strings.HasPrefix(arg, "--puk=") {
+			return nil, "", fmt.Errorf("%s on the command line is visible to other users, pass it on stdin or in the environment", strings.ToUpper(arg[2:5]))
+		} else if len(arg) > 8 && arg[:8] == "--rules=" {
+			cmd.Rules = arg[8:]
+		} else if len(arg) > 7 && arg[:7] == "--exec=" {
+			cmd.Exec = arg[7:]
+		} else if len(arg) > 9 && arg[:9] == "--listen=" {
+			cmd.Listen = arg[9:]
+		} else if len(arg) > 8 && arg[:8] == "--token=" {
+			cmd.Token = arg[8:]
+		} else if len(arg) > 6 && arg[:6] == "--out=" {
+			cmd.Out = arg[6:]
+		} else if len(arg) > 7 && arg[:7] == "--page=" {
+			page, err := strconv.Atoi(arg[7:])
+			if err != nil || page < 1 {
+				return nil, "", fmt.Errorf("invalid page: %s", arg[7:])
+			}
+			cmd.Page = page
 		} else if len(arg) > 7 && arg[:7] == "--auth=" {
 			cmd.Auth = arg[7:]
 		} else if len(arg) > 7 && arg[:7] == "--host=" {
@@ -75,17 +271,71 @@ func ParseArgs(args []string) (*SMSCommand, string, error) {
 		cmd.Folder = "inbox"
 	}
 
+	// The router clock has no zone, it's read in the given one
+	if cmd.Timezone != "" {
+		location, err := time.LoadLocation(cmd.Timezone)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid timezone: %s", cmd.Timezone)
+		}
+		cmd.Location = location
+	}
+
+	// A template selects the template format
+	if cmd.Template != "" && cmd.Format == "" {
+		cmd.Format = "template"
+	}
+
 	return cmd, subcommand, nil
 }
 
+// valueFormats are the --format values of commands which print a value
+// or table, see outputTable
+var valueFormats = []string{"table", "json", "yaml"}
+
+// CheckFormat returns an error if --format is not one of the formats a
+// command supports. ParseArgs only checks that the format is known.
+func (c *SMSCommand) CheckFormat(formats ...string) error {
+	if c.Format == "" || slices.Contains(formats, c.Format) {
+		return nil
+	}
+	return fmt.Errorf("format %q is not supported for this command, use %s", c.Format, strings.Join(formats, ", "))
+}
+
+// parseRateLimit parses a rate limit given as <count>/<duration>, e.g. 10/1h
+func parseRateLimit(s string) (int, time.Duration, error) {
+	count, window, ok := strings.Cut(s, "/")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid rate limit %q, expected <count>/<duration>", s)
+	}
+	limit, err := strconv.Atoi(count)
+	if err != nil || limit < 1 {
+		return 0, 0, fmt.Errorf("invalid rate limit count: %s", count)
+	}
+	duration, err := time.ParseDuration(window)
+	if err != nil || duration <= 0 {
+		return 0, 0, fmt.Errorf("invalid rate limit window: %s", window)
+	}
+	return limit, duration, nil
+}
+
 // ListSMS lists SMS messages
 func (c *SMSCommand) ListSMS(ctx context.Context) error {
-	smsClient, err := client.NewSMSClient(c.ClientOptions())
+	smsClient, err := c.NewClient()
 	if err != nil {
-		return fmt.Errorf("failed to create client: %w", err)
+		return err
 	}
+	defer c.CloseClient(ctx, smsClient)
 
-	resp, err := smsClient.List(ctx, c.Folder)
+	var resp *model.ListResponse
+	if c.All {
+		resp, err = smsClient.ListAll(ctx, c.Folder)
+	} else {
+		page := c.Page
+		if page == 0 {
+			page = 1
+		}
+		resp, err = smsClient.ListPage(ctx, c.Folder, page)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to list SMS: %w", err)
 	}
@@ -94,18 +344,16 @@ func (c *SMSCommand) ListSMS(ctx context.Context) error {
 		return fmt.Errorf("router returned error code: %d", resp.Error)
 	}
 
-	if c.JSON {
-		return c.outputJSON(resp.Data)
-	}
-	return c.outputTable(resp.Data)
+	return c.output(resp.Data, "table")
 }
 
 // ReadSMS reads a specific SMS message
 func (c *SMSCommand) ReadSMS(ctx context.Context, index int) error {
-	smsClient, err := client.NewSMSClient(c.ClientOptions())
+	smsClient, err := c.NewClient()
 	if err != nil {
-		return fmt.Errorf("failed to create client: %w", err)
+		return err
 	}
+	defer c.CloseClient(ctx, smsClient)
 
 	resp, err := smsClient.Read(ctx, c.Folder, index)
 	if err != nil {
@@ -116,38 +364,16 @@ func (c *SMSCommand) ReadSMS(ctx context.Context, index int) error {
 		return fmt.Errorf("router returned error code: %d", resp.Error)
 	}
 
-	if c.JSON {
-		return c.outputJSON(resp.Data)
-	}
-
-	// Output a single message in readable format
-	if len(resp.Data) == 0 {
-		fmt.Println("No message found")
-		return nil
-	}
-
-	msg := resp.Data[0]
-	fmt.Printf("Index: %d\n", msg.Index)
-
-	if c.Folder == "inbox" {
-		fmt.Printf("From: %s\n", msg.From)
-		fmt.Printf("Received: %s\n", formatTime(msg.RecvTime))
-		fmt.Printf("Unread: %v\n", msg.Unread)
-	} else {
-		fmt.Printf("To: %s\n", msg.To)
-		fmt.Printf("Sent: %s\n", formatTime(msg.SentTime))
-	}
-
-	fmt.Printf("\nMessage:\n%s\n", msg.Content)
-	return nil
+	return c.output(resp.Data, "text")
 }
 
 // DeleteSMS deletes a specific SMS message by position
 func (c *SMSCommand) DeleteSMS(ctx context.Context, index int) error {
-	smsClient, err := client.NewSMSClient(c.ClientOptions())
+	smsClient, err := c.NewClient()
 	if err != nil {
-		return fmt.Errorf("failed to create client: %w", err)
+		return err
 	}
+	defer c.CloseClient(ctx, smsClient)
 
 	resp, err := smsClient.Delete(ctx, c.Folder, index)
 	if err != nil {
@@ -164,10 +390,11 @@ func (c *SMSCommand) DeleteSMS(ctx context.Context, index int) error {
 
 // SendSMS sends an SMS message to a phone number
 func (c *SMSCommand) SendSMS(ctx context.Context, number, message string) error {
-	smsClient, err := client.NewSMSClient(c.ClientOptions())
+	smsClient, err := c.NewClient()
 	if err != nil {
-		return fmt.Errorf("failed to create client: %w", err)
+		return err
 	}
+	defer c.CloseClient(ctx, smsClient)
 
 	resp, err := smsClient.Send(ctx, number, message)
 	if err != nil {
@@ -184,107 +411,118 @@ func (c *SMSCommand) SendSMS(ctx context.Context, number, message string) error
 
 // DeleteSMSByID deletes a specific SMS message by its ID (index)
 func (c *SMSCommand) DeleteSMSByID(ctx context.Context, msgID int) error {
-	smsClient, err := client.NewSMSClient(c.ClientOptions())
+	smsClient, err := c.NewClient()
 	if err != nil {
-		return fmt.Errorf("failed to create client: %w", err)
+		return err
 	}
+	defer c.CloseClient(ctx, smsClient)
 
-	// First, get the list to find the position of the message with this ID
-	resp, err := smsClient.List(ctx, c.Folder)
+	// The message is searched on every page of the folder
+	delResp, err := smsClient.DeleteByIndex(ctx, c.Folder, msgID)
+	if errors.Is(err, client.ErrNotFound) {
+		return fmt.Errorf("message with ID %d not found in %s folder", msgID, c.Folder)
+	}
 	if err != nil {
-		return fmt.Errorf("failed to list SMS: %w", err)
+		return fmt.Errorf("failed to delete SMS: %w", err)
 	}
 
-	if resp.Error != 0 {
-		return fmt.Errorf("router returned error code: %d", resp.Error)
+	if delResp.Error != 0 {
+		return fmt.Errorf("router returned error code: %d", delResp.Error)
 	}
 
-	// Find the message with matching ID
-	var position int
-	found := false
-	for i, msg := range resp.Data {
-		if msg.Index == msgID {
-			position = i + 1 // Convert to 1-based position
-			found = true
-			break
-		}
-	}
+	fmt.Printf("Message with ID %d deleted\n", msgID)
+	return nil
+}
 
-	if !found {
-		return fmt.Errorf("message with ID %d not found in %s folder", msgID, c.Folder)
+// BackupSMS writes all inbox and sent messages to a JSON lines archive
+func (c *SMSCommand) BackupSMS(ctx context.Context) error {
+	if c.Out == "" {
+		return fmt.Errorf("backup requires --out=<file>")
 	}
 
-	// Delete using the position
-	delResp, err := smsClient.Delete(ctx, c.Folder, position)
+	smsClient, err := c.NewClient()
 	if err != nil {
-		return fmt.Errorf("failed to delete SMS: %w", err)
+		return err
 	}
+	defer c.CloseClient(ctx, smsClient)
 
-	if delResp.Error != 0 {
-		return fmt.Errorf("router returned error code: %d", delResp.Error)
+	var messages []model.SMSMessage
+	for _, folder := range []string{"inbox", "sent"} {
+		resp, err := smsClient.ListAll(ctx, folder)
+		if err != nil {
+			return fmt.Errorf("failed to list %s: %w", folder, err)
+		}
+		messages = append(messages, resp.Data...)
+	}
+
+	if c.Incremental {
+		added, err := archive.Append(c.Out, messages)
+		if err != nil {
+			return fmt.Errorf("failed to append to archive: %w", err)
+		}
+		fmt.Printf("Added %d new messages to %s\n", added, c.Out)
+		return nil
 	}
 
-	fmt.Printf("Message with ID %d (position %d) deleted\n", msgID, position)
+	written, err := archive.Write(c.Out, messages)
+	if err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+	fmt.Printf("Wrote %d messages to %s\n", written, c.Out)
 	return nil
 }
 
-// outputTable formats SMS messages as a markdown table
-func (c *SMSCommand) outputTable(messages []model.SMSMessage) error {
-	var headers []string
-	rows := [][]string{}
-
-	if c.Folder == "inbox" {
-		headers = []string{"#", "ID", "Sender", "Message", "Date/Age"}
-		for i, msg := range messages {
-			rows = append(rows, []string{
-				fmt.Sprintf("%d", i+1),
-				fmt.Sprintf("%d", msg.Index),
-				msg.From,
-				msg.Content,
-				formatTime(msg.RecvTime),
-			})
-		}
-	} else {
-		headers = []string{"#", "ID", "To", "Message", "Date/Age"}
-		for i, msg := range messages {
-			rows = append(rows, []string{
-				fmt.Sprintf("%d", i+1),
-				fmt.Sprintf("%d", msg.Index),
-				msg.To,
-				msg.Content,
-				formatTime(msg.SentTime),
-			})
-		}
+// Logout ends the router session, including a cached one
+func (c *SMSCommand) Logout(ctx context.Context) error {
+	// Always consult the cache, so a cached session is ended and removed
+	opts := c.ClientOptions()
+	if opts.SessionCache == nil {
+		opts.SessionCache = client.NewSessionCache("")
 	}
 
-	table := tablewriter.NewTable(
-		os.Stdout,
-		tablewriter.WithHeader(headers),
-		tablewriter.WithColumnMax(180), // Set a wider column width
-	)
+	smsClient, err := client.NewSMSClient(opts)
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
 
-	for _, row := range rows {
-		table.Append(row)
+	if err := smsClient.Logout(ctx); err != nil {
+		return fmt.Errorf("failed to log out: %w", err)
 	}
 
-	table.Render()
+	fmt.Printf("Logged out of %s\n", c.Host)
 	return nil
 }
 
-// outputJSON formats SMS messages as indented JSON
-func (c *SMSCommand) outputJSON(messages []model.SMSMessage) error {
-	data, err := json.MarshalIndent(messages, "", "  ")
+// output writes messages in the selected format, or the default one
+func (c *SMSCommand) output(messages []model.SMSMessage, defaultFormat string) error {
+	format := c.Format
+	if format == "" && c.JSON {
+		format = "json"
+	}
+	if format == "" {
+		format = defaultFormat
+	}
+
+	formatter, err := GetFormatter(format)
 	if err != nil {
-		return fmt.Errorf("failed to marshal JSON: %w", err)
+		return err
+	}
+	return formatter(os.Stdout, c.FormatOptions(), messages)
+}
+
+// FormatOptions returns the settings passed to formatters
+func (c *SMSCommand) FormatOptions() FormatOptions {
+	return FormatOptions{
+		Folder:   c.Folder,
+		Template: c.Template,
 	}
-	fmt.Println(string(data))
-	return nil
 }
 
-// truncate truncates a string to max length
+// truncate truncates a string to max characters
 func truncate(s string, max int) string {
-	if len(s) > max {
-		return s[:max] + "..."
+	runes := []rune(s)
+	if len(runes) > max {
+		return string(runes[:max]) + "..."
 	}
 	return s
 }
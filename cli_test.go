@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/titpetric/tp-link-cli/archive"
+	"github.com/titpetric/tp-link-cli/fakerouter"
+)
+
+func TestDeleteSMSByID(t *testing.T) {
+	router := fakerouter.New("admin", "secret")
+	defer router.Close()
+
+	now := time.Now()
+	for i := 0; i < 12; i++ {
+		router.Receive("+38640111222", "message", now.Add(time.Duration(i)*time.Minute))
+	}
+
+	// The oldest messages are on the second page of the inbox
+	c := &SMSCommand{Auth: "admin:secret", Host: router.URL(), Folder: "inbox"}
+	require.NoError(t, c.DeleteSMSByID(context.Background(), 2))
+
+	assert.Len(t, router.Inbox(), 11)
+	for _, msg := range router.Inbox() {
+		assert.NotEqual(t, 2, msg.Index)
+	}
+
+	err := c.DeleteSMSByID(context.Background(), 2)
+	assert.ErrorContains(t, err, "message with ID 2 not found in inbox folder")
+}
+
+func TestParseArgsRejectsPassword(t *testing.T) {
+	_, _, err := ParseArgs([]string{"add", "--password=secret"})
+	assert.ErrorContains(t, err, "visible to other users")
+
+	cmd, _, err := ParseArgs([]string{"add", "--password"})
+	require.NoError(t, err)
+	assert.True(t, cmd.ReadPassword)
+}
+
+func TestParseArgsTimezone(t *testing.T) {
+	t.Setenv("TP_LINK_CLI_TIMEZONE", "")
+
+	cmd, _, err := ParseArgs([]string{"list"})
+	require.NoError(t, err)
+	assert.Nil(t, cmd.ClientOptions().Location)
+
+	cmd, _, err = ParseArgs([]string{"list", "--timezone=Europe/Ljubljana"})
+	require.NoError(t, err)
+	assert.Equal(t, "Europe/Ljubljana", cmd.ClientOptions().Location.String())
+
+	_, _, err = ParseArgs([]string{"list", "--timezone=Mars/Olympus"})
+	assert.ErrorContains(t, err, "invalid timezone")
+}
+
+func TestBackupSMSKeepsDeleted(t *testing.T) {
+	router := fakerouter.New("admin", "secret")
+	defer router.Close()
+
+	now := time.Now()
+	deleted := router.Receive("+38640111222", "deleted later", now.Add(-time.Hour))
+	router.Receive("+38640111222", "kept", now.Add(-time.Minute))
+
+	out := filepath.Join(t.TempDir(), "archive.jsonl")
+	c := &SMSCommand{Auth: "admin:secret", Host: router.URL(), Folder: "inbox", Out: out}
+	require.NoError(t, c.BackupSMS(context.Background()))
+
+	require.NoError(t, c.DeleteSMSByID(context.Background(), deleted))
+	router.Receive("+38640111222", "new", now)
+	require.NoError(t, c.BackupSMS(context.Background()))
+
+	messages, err := archive.Read(out)
+	require.NoError(t, err)
+	var contents []string
+	for _, msg := range messages {
+		contents = append(contents, msg.Content)
+	}
+	assert.Equal(t, []string{"deleted later", "kept", "new"}, contents)
+}
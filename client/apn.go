@@ -0,0 +1,241 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/titpetric/tp-link-cli/model"
+)
+
+// apnController holds the APN profiles, one object per profile. The
+// active profile is the profileID of WAN_LTE_LINK_CFG.
+const apnController = "LTE_PROF_CFG"
+
+// authTypes names the authType values of a profile.
+var authTypes = map[int]string{
+	0: "none",
+	1: "pap",
+	2: "chap",
+}
+
+// pdpTypes names the pdpType values of a profile.
+var pdpTypes = map[int]string{
+	0: "ipv4",
+	1: "ipv6",
+	2: "ipv4v6",
+}
+
+// apnRegex matches an APN: labels of letters, digits and dashes,
+// separated by dots.
+var apnRegex = regexp.MustCompile(`^[A-Za-z0-9-]+(\.[A-Za-z0-9-]+)*$`)
+
+// APNProfiles lists the APN profiles, marking the active one.
+func (c *SMSClient) APNProfiles(ctx context.Context) ([]model.APNProfile, error) {
+	resp, err := c.execute(ctx, []Request{
+		{Method: ActGL, Controller: apnController},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if resp.Error != 0 {
+		return nil, fmt.Errorf("router returned error code: %d", resp.Error)
+	}
+
+	link, err := c.status(ctx, []Request{
+		{Method: ActGet, Controller: lteLinkController, Stack: lteStack, Attrs: []string{"profileID"}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	active := attrString(link, "profileID")
+
+	result := make([]model.APNProfile, 0, len(resp.Data))
+	for _, obj := range resp.Data {
+		profile := apnFromAttrs(obj)
+		profile.Active = attrString(obj, "profileID") == active
+		result = append(result, profile)
+	}
+	return result, nil
+}
+
+// APNProfile returns the profile with the ID.
+func (c *SMSClient) APNProfile(ctx context.Context, id int) (*model.APNProfile, error) {
+	profile, _, err := c.findAPNProfile(ctx, id)
+	return profile, err
+}
+
+// AddAPNProfile validates and adds a profile, returning its ID.
+func (c *SMSClient) AddAPNProfile(ctx context.Context, profile model.APNProfile) (int, error) {
+	attrs, err := apnAttrs(profile)
+	if err != nil {
+		return 0, err
+	}
+
+	resp, err := c.execute(ctx, []Request{
+		{Method: ActAdd, Controller: apnController, Attrs: attrs},
+	})
+	if err != nil {
+		return 0, err
+	}
+	if resp.Error != 0 {
+		return 0, fmt.Errorf("router returned error code: %d", resp.Error)
+	}
+	if len(resp.Data) == 0 {
+		return 0, fmt.Errorf("router returned no profile")
+	}
+	return int(attrFloat(resp.Data[0], "profileID")), nil
+}
+
+// EditAPNProfile validates and saves a profile, by its ID. Built-in
+// profiles can't be changed. The stored password is kept, unless the
+// profile has a new one.
+func (c *SMSClient) EditAPNProfile(ctx context.Context, profile model.APNProfile) error {
+	attrs, err := apnAttrs(profile)
+	if err != nil {
+		return err
+	}
+
+	current, pos, err := c.findAPNProfile(ctx, profile.ID)
+	if err != nil {
+		return err
+	}
+	if current.Default {
+		return fmt.Errorf("profile %d is built in and can't be changed", profile.ID)
+	}
+
+	return c.apnAction(ctx, Request{Method: ActSet, Controller: apnController, Stack: apnStack(pos), Attrs: attrs})
+}
+
+// DeleteAPNProfile deletes a profile by its ID. Built-in profiles and
+// the active profile can't be deleted.
+func (c *SMSClient) DeleteAPNProfile(ctx context.Context, id int) error {
+	current, pos, err := c.findAPNProfile(ctx, id)
+	if err != nil {
+		return err
+	}
+	if current.Default {
+		return fmt.Errorf("profile %d is built in and can't be deleted", id)
+	}
+	if current.Active {
+		return fmt.Errorf("profile %d is active, activate another profile first", id)
+	}
+
+	return c.apnAction(ctx, Request{Method: ActDel, Controller: apnController, Stack: apnStack(pos)})
+}
+
+// ActivateAPNProfile makes the profile with the ID the active one.
+func (c *SMSClient) ActivateAPNProfile(ctx context.Context, id int) error {
+	if _, _, err := c.findAPNProfile(ctx, id); err != nil {
+		return err
+	}
+
+	return c.apnAction(ctx, Request{
+		Method:     ActSet,
+		Controller: lteLinkController,
+		Stack:      lteStack,
+		Attrs: map[string]interface{}{
+			"profileID": id,
+		},
+	})
+}
+
+// findAPNProfile returns the profile with the ID and its 1-based
+// position in the list, which addresses it in requests.
+func (c *SMSClient) findAPNProfile(ctx context.Context, id int) (*model.APNProfile, int, error) {
+	profiles, err := c.APNProfiles(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	for i, profile := range profiles {
+		if profile.ID == id {
+			return &profile, i + 1, nil
+		}
+	}
+	return nil, 0, fmt.Errorf("profile %d not found", id)
+}
+
+func (c *SMSClient) apnAction(ctx context.Context, req Request) error {
+	resp, err := c.execute(ctx, []Request{req})
+	if err != nil {
+		return err
+	}
+	if resp.Error != 0 {
+		return fmt.Errorf("router returned error code: %d", resp.Error)
+	}
+	return nil
+}
+
+func apnStack(pos int) string {
+	return fmt.Sprintf("%d,0,0,0,0,0", pos)
+}
+
+func apnFromAttrs(obj map[string]interface{}) model.APNProfile {
+	return model.APNProfile{
+		ID:       int(attrFloat(obj, "profileID")),
+		Name:     attrString(obj, "profileName"),
+		APN:      attrString(obj, "apn"),
+		AuthType: lookupName(authTypes, obj, "authType"),
+		Username: attrString(obj, "username"),
+		PDPType:  lookupName(pdpTypes, obj, "pdpType"),
+		Default:  attrString(obj, "profileType") == "0",
+	}
+}
+
+// apnAttrs validates a profile and returns its attributes for a set.
+// The password is only sent when given, as it isn't read back.
+func apnAttrs(p model.APNProfile) (map[string]interface{}, error) {
+	if p.Name == "" || len(p.Name) > 32 {
+		return nil, fmt.Errorf("profile name must be 1 to 32 characters")
+	}
+	if len(p.APN) > 63 || !apnRegex.MatchString(p.APN) {
+		return nil, fmt.Errorf("invalid APN: %q", p.APN)
+	}
+
+	authType, ok := lookupValue(authTypes, p.AuthType)
+	if !ok {
+		return nil, fmt.Errorf("invalid auth type %q, use none, pap or chap", p.AuthType)
+	}
+	if authType != 0 && p.Username == "" {
+		return nil, fmt.Errorf("auth type %s requires a username", p.AuthType)
+	}
+
+	pdpType, ok := lookupValue(pdpTypes, p.PDPType)
+	if !ok {
+		return nil, fmt.Errorf("invalid PDP type %q, use ipv4, ipv6 or ipv4v6", p.PDPType)
+	}
+
+	attrs := map[string]interface{}{
+		"profileName": p.Name,
+		"apn":         p.APN,
+		"authType":    authType,
+		"pdpType":     pdpType,
+		"username":    "",
+		"password":    "",
+	}
+	if authType != 0 {
+		attrs["username"] = p.Username
+		// Without a new password, the stored one is left as is
+		delete(attrs, "password")
+		if p.Password != "" {
+			attrs["password"] = p.Password
+		}
+	}
+	return attrs, nil
+}
+
+// lookupValue returns the value of a name in names, the reverse of
+// lookupName. A number is accepted as is.
+func lookupValue(names map[int]string, name string) (int, bool) {
+	for value, n := range names {
+		if n == name {
+			return value, true
+		}
+	}
+	if value, err := strconv.Atoi(name); err == nil {
+		_, ok := names[value]
+		return value, ok
+	}
+	return 0, false
+}
@@ -0,0 +1,123 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/titpetric/tp-link-cli/fakerouter"
+	"github.com/titpetric/tp-link-cli/model"
+)
+
+func setAPNProfiles(router *fakerouter.Router) {
+	router.SetStatus("WAN_LTE_LINK_CFG", map[string]string{"profileID": "1"})
+	router.SetAPNProfiles([]map[string]string{
+		{"profileName": "A1", "apn": "internet", "authType": "0", "pdpType": "2", "profileType": "0"},
+		{"profileName": "Work", "apn": "corp.example", "authType": "1", "username": "user", "password": "pass", "pdpType": "0"},
+	})
+}
+
+func TestAPNProfiles(t *testing.T) {
+	c, router := newTestClient(t)
+	setAPNProfiles(router)
+
+	profiles, err := c.APNProfiles(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []model.APNProfile{
+		{ID: 1, Name: "A1", APN: "internet", AuthType: "none", PDPType: "ipv4v6", Default: true, Active: true},
+		{ID: 2, Name: "Work", APN: "corp.example", AuthType: "pap", Username: "user", PDPType: "ipv4"},
+	}, profiles)
+
+	// A password given for an edit is not printed with the profile
+	profiles[1].Password = "pass"
+	body, err := json.Marshal(profiles)
+	require.NoError(t, err)
+	assert.NotContains(t, string(body), "pass")
+}
+
+func TestAPNProfileChanges(t *testing.T) {
+	c, router := newTestClient(t)
+	setAPNProfiles(router)
+	ctx := context.Background()
+
+	id, err := c.AddAPNProfile(ctx, model.APNProfile{Name: "Travel", APN: "roam.example", AuthType: "none", PDPType: "ipv4"})
+	require.NoError(t, err)
+	assert.Equal(t, 3, id)
+
+	profile, err := c.APNProfile(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, "roam.example", profile.APN)
+
+	profile.AuthType, profile.Username, profile.Password = "chap", "roamer", "secret"
+	require.NoError(t, c.EditAPNProfile(ctx, *profile))
+	stored := router.APNProfiles()[2]
+	assert.Equal(t, "2", stored["authType"])
+	assert.Equal(t, "roamer", stored["username"])
+	assert.Equal(t, "secret", stored["password"])
+
+	require.NoError(t, c.ActivateAPNProfile(ctx, id))
+	assert.Equal(t, "3", router.Status("WAN_LTE_LINK_CFG")["profileID"])
+
+	assert.ErrorContains(t, c.DeleteAPNProfile(ctx, id), "active")
+	assert.ErrorContains(t, c.DeleteAPNProfile(ctx, 1), "built in")
+	assert.ErrorContains(t, c.DeleteAPNProfile(ctx, 9), "not found")
+
+	require.NoError(t, c.DeleteAPNProfile(ctx, 2))
+	profiles, err := c.APNProfiles(ctx)
+	require.NoError(t, err)
+	require.Len(t, profiles, 2)
+	assert.Equal(t, 3, profiles[1].ID)
+}
+
+func TestAPNProfileEditKeepsPassword(t *testing.T) {
+	c, router := newTestClient(t)
+	setAPNProfiles(router)
+	ctx := context.Background()
+
+	// The router doesn't return the password, an edit leaves it as is
+	profile, err := c.APNProfile(ctx, 2)
+	require.NoError(t, err)
+	assert.Empty(t, profile.Password)
+
+	profile.Name, profile.AuthType = "Office", "chap"
+	require.NoError(t, c.EditAPNProfile(ctx, *profile))
+	stored := router.APNProfiles()[1]
+	assert.Equal(t, "Office", stored["profileName"])
+	assert.Equal(t, "pass", stored["password"])
+
+	// Without authentication, the credentials are cleared
+	profile.AuthType = "none"
+	require.NoError(t, c.EditAPNProfile(ctx, *profile))
+	stored = router.APNProfiles()[1]
+	assert.Empty(t, stored["username"])
+	assert.Empty(t, stored["password"])
+}
+
+func TestAPNProfileValidation(t *testing.T) {
+	c, router := newTestClient(t)
+	setAPNProfiles(router)
+	ctx := context.Background()
+
+	valid := model.APNProfile{Name: "Travel", APN: "roam.example", AuthType: "none", PDPType: "ipv4"}
+	for name, change := range map[string]func(p *model.APNProfile){
+		"name":      func(p *model.APNProfile) { p.Name = "" },
+		"apn":       func(p *model.APNProfile) { p.APN = "bad apn" },
+		"auth type": func(p *model.APNProfile) { p.AuthType = "kerberos" },
+		"username":  func(p *model.APNProfile) { p.AuthType = "pap" },
+		"pdp type":  func(p *model.APNProfile) { p.PDPType = "ipx" },
+	} {
+		profile := valid
+		change(&profile)
+		_, err := c.AddAPNProfile(ctx, profile)
+		assert.Error(t, err, name)
+	}
+	assert.Len(t, router.APNProfiles(), 2)
+
+	// Built-in profiles can't be changed
+	builtin := valid
+	builtin.ID = 1
+	assert.ErrorContains(t, c.EditAPNProfile(ctx, builtin), "built in")
+}
@@ -2,8 +2,10 @@ package client
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"iter"
 	"net/http"
 	"net/http/cookiejar"
 	"net/url"
@@ -14,10 +16,41 @@ import (
 	"github.com/titpetric/tp-link-cli/model"
 )
 
+// ErrNotFound is returned when a message with the given index does not exist.
+var ErrNotFound = errors.New("message not found")
+
+// ErrSessionExpired is returned when the router rejects the session.
+var ErrSessionExpired = errors.New("session expired")
+
+// sessionErrorCodes are router error codes reported for an invalid session.
+var sessionErrorCodes = map[int]bool{
+	71233: true,
+	71234: true,
+}
+
 // Options holds client configuration.
 type Options struct {
 	Auth string // "username:password"
 	Host string // "192.168.1.1" or "http://192.168.1.1"
+
+	// SessionCache, if set, stores the session between client instances.
+	SessionCache *SessionCache
+
+	// Observer, if set, is notified of logins and requests.
+	Observer Observer
+
+	// Location is the time zone of the router clock, time.Local if nil.
+	Location *time.Location
+}
+
+// Observer receives client events, for metrics.
+type Observer interface {
+	// ObserveRequest is called for each controller in a request frame,
+	// with the duration of the frame, the router error code and the
+	// request error.
+	ObserveRequest(controller string, duration time.Duration, code int, err error)
+	// ObserveLogin is called for each login, with the login error.
+	ObserveLogin(err error)
 }
 
 // SMSClient communicates with TP-Link router.
@@ -31,6 +64,14 @@ type SMSClient struct {
 	enc        *Encryption
 	proto      *Protocol
 	httpClient *http.Client
+
+	rsaModulus  string
+	rsaExponent string
+
+	cache  *SessionCache
+	cached bool // session was restored from cache and is not yet confirmed
+
+	observer Observer
 }
 
 // NewSMSClient creates a new SMS client.
@@ -53,14 +94,19 @@ func NewSMSClient(opts *Options) (*SMSClient, error) {
 		baseURL = "http://" + baseURL
 	}
 
+	proto := NewProtocol()
+	proto.Location = opts.Location
+
 	jar, _ := cookiejar.New(&cookiejar.Options{})
 	return &SMSClient{
 		baseURL:    baseURL,
 		username:   username,
 		password:   password,
 		enc:        NewEncryption(),
-		proto:      NewProtocol(),
+		proto:      proto,
 		httpClient: &http.Client{Jar: jar},
+		cache:      opts.SessionCache,
+		observer:   opts.Observer,
 	}, nil
 }
 
@@ -127,6 +173,8 @@ func (c *SMSClient) Connect(ctx context.Context) error {
 	if err := c.enc.SetRSAKey(nn, ee); err != nil {
 		return err
 	}
+	c.rsaModulus = nn
+	c.rsaExponent = ee
 	c.enc.GenAESKey()
 	// Convert seq string to int
 	seqNum := 0
@@ -265,19 +313,142 @@ func (c *SMSClient) Connect(ctx context.Context) error {
 		return fmt.Errorf("failed to extract token ID from homepage\nResponse: %s", bodyStr)
 	}
 	c.TokenID = matches[1]
+	c.cached = false
+
+	// Cache the session for the next client; failing to do so is not fatal
+	if c.cache != nil {
+		c.cache.Save(c.Session())
+	}
 
 	return nil
 }
 
+// Host returns the router host, without the URL scheme.
+func (c *SMSClient) Host() string {
+	host := strings.TrimPrefix(c.baseURL, "http://")
+	return strings.TrimPrefix(host, "https://")
+}
+
+// Session returns the current session state.
+func (c *SMSClient) Session() *Session {
+	key, iv := c.enc.GetAESKey()
+	return &Session{
+		Host:        c.baseURL,
+		Username:    c.username,
+		SessionID:   c.SessionID,
+		TokenID:     c.TokenID,
+		AESKey:      key,
+		AESIV:       iv,
+		Seq:         c.enc.GetSeq(),
+		RSAModulus:  c.rsaModulus,
+		RSAExponent: c.rsaExponent,
+		Created:     time.Now(),
+	}
+}
+
+// SetSession restores a previously obtained session.
+func (c *SMSClient) SetSession(s *Session) error {
+	if s.SessionID == "" || s.TokenID == "" {
+		return fmt.Errorf("incomplete session")
+	}
+	if err := c.enc.SetRSAKey(s.RSAModulus, s.RSAExponent); err != nil {
+		return err
+	}
+	if err := c.enc.SetAESKey(s.AESKey, s.AESIV); err != nil {
+		return err
+	}
+	c.enc.SetSeq(s.Seq)
+	c.enc.SetHash(c.username, c.password)
+
+	c.rsaModulus = s.RSAModulus
+	c.rsaExponent = s.RSAExponent
+	c.SessionID = s.SessionID
+	c.TokenID = s.TokenID
+	return nil
+}
+
+// authenticate restores a cached session if available, or logs in.
+func (c *SMSClient) authenticate(ctx context.Context) error {
+	if c.cache != nil {
+		session, err := c.cache.Load(c.baseURL, c.username)
+		if err == nil && session != nil && c.SetSession(session) == nil {
+			c.cached = true
+			return nil
+		}
+	}
+	return c.login(ctx)
+}
+
+// login logs in, reporting the result to the observer.
+func (c *SMSClient) login(ctx context.Context) error {
+	err := c.Connect(ctx)
+	if c.observer != nil {
+		c.observer.ObserveLogin(err)
+	}
+	return err
+}
+
 // execute sends an encrypted request to the router.
 func (c *SMSClient) execute(ctx context.Context, reqs []Request) (Response, error) {
 	// Ensure we're authenticated
 	if c.TokenID == "" {
-		if err := c.Connect(ctx); err != nil {
+		if err := c.authenticate(ctx); err != nil {
 			return Response{}, err
 		}
 	}
 
+	resp, err := c.observe(ctx, reqs)
+	if err != nil && c.cache != nil && c.cached {
+		// A cached session which fails is not reused by the next client
+		c.cache.Delete(c.baseURL, c.username)
+	}
+	c.cached = false
+
+	// Only a session rejected before the requests ran is safe to replay.
+	// Other errors, such as a response which can't be decrypted, may
+	// arrive after the router acted, and a replay could repeat a send,
+	// delete or reboot.
+	if errors.Is(err, ErrSessionExpired) && ctx.Err() == nil {
+		c.SessionID = ""
+		c.TokenID = ""
+		if err := c.login(ctx); err != nil {
+			return Response{}, err
+		}
+		return c.observe(ctx, reqs)
+	}
+	return resp, err
+}
+
+// Do sends the requests to the router over the session, logging in as
+// needed. It gives access to controllers without a typed method, the
+// response data is converted as by PrettifyResponse.
+func (c *SMSClient) Do(ctx context.Context, reqs []Request) (Response, error) {
+	return c.execute(ctx, reqs)
+}
+
+// observe sends the requests, reporting each controller to the observer.
+func (c *SMSClient) observe(ctx context.Context, reqs []Request) (Response, error) {
+	if c.observer == nil {
+		return c.send(ctx, reqs)
+	}
+
+	start := time.Now()
+	resp, err := c.send(ctx, reqs)
+	duration := time.Since(start)
+
+	seen := map[string]bool{}
+	for _, req := range reqs {
+		if seen[req.Controller] {
+			continue
+		}
+		seen[req.Controller] = true
+		c.observer.ObserveRequest(req.Controller, duration, resp.Error, err)
+	}
+	return resp, err
+}
+
+// send encrypts and posts the requests using the current session.
+func (c *SMSClient) send(ctx context.Context, reqs []Request) (Response, error) {
 	// Build and encrypt frame
 	dataFrame := c.proto.MakeDataFrame(reqs)
 	encrypted := c.enc.AESEncrypt(dataFrame, false)
@@ -305,22 +476,74 @@ func (c *SMSClient) execute(ctx context.Context, reqs []Request) (Response, erro
 		return Response{}, err
 	}
 
-	// Decrypt response
-	decrypted, err := c.enc.AESDecrypt(string(respBody))
+	// The router answers an invalid session with 403, or redirects to login
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return Response{}, fmt.Errorf("%w: status %d", ErrSessionExpired, resp.StatusCode)
+	}
+	if resp.Request.URL.Path != req.URL.Path {
+		return Response{}, fmt.Errorf("%w: redirected to %s", ErrSessionExpired, resp.Request.URL.Path)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return Response{}, fmt.Errorf("request returned status %d", resp.StatusCode)
+	}
+
+	// The requests may have run, so a response we can't read is not
+	// reported as an expired session
+	decrypted, err := c.enc.AESDecrypt(strings.TrimSpace(string(respBody)))
 	if err != nil {
 		return Response{}, fmt.Errorf("failed to decrypt response: %w", err)
 	}
+	if !strings.Contains(decrypted, "[error]") {
+		return Response{}, fmt.Errorf("unexpected response")
+	}
 
 	// Parse response
 	parsed := c.proto.FromDataFrame(decrypted)
+	if sessionErrorCodes[parsed.Error] {
+		return Response{}, fmt.Errorf("%w: error code %d", ErrSessionExpired, parsed.Error)
+	}
 	return c.proto.PrettifyResponse(parsed), nil
 }
 
-// List retrieves SMS messages from the specified folder.
+// Logout ends the router session, freeing the single admin web session.
+func (c *SMSClient) Logout(ctx context.Context) error {
+	reqs := []Request{
+		{
+			Method:     ActCGI,
+			Controller: "/cgi/logout",
+		},
+	}
+
+	resp, err := c.execute(ctx, reqs)
+	if err != nil {
+		return err
+	}
+	if resp.Error != 0 {
+		return fmt.Errorf("router returned error code: %d", resp.Error)
+	}
+
+	if c.cache != nil {
+		c.cache.Delete(c.baseURL, c.username)
+	}
+	c.SessionID = ""
+	c.TokenID = ""
+	return nil
+}
+
+// List retrieves the first page of SMS messages from the specified folder.
 func (c *SMSClient) List(ctx context.Context, folder string) (*model.ListResponse, error) {
+	return c.ListPage(ctx, folder, 1)
+}
+
+// ListPage retrieves a page of SMS messages from the specified folder.
+// Pages are 1-based, the router returns up to 8 messages per page.
+func (c *SMSClient) ListPage(ctx context.Context, folder string, page int) (*model.ListResponse, error) {
 	if folder == "" {
 		folder = "inbox"
 	}
+	if page < 1 {
+		return nil, fmt.Errorf("invalid page: %d", page)
+	}
 
 	var reqs []Request
 
@@ -340,12 +563,12 @@ func (c *SMSClient) List(ctx context.Context, folder string) (*model.ListRespons
 		return nil, fmt.Errorf("invalid folder: %s", folder)
 	}
 
-	// Reset cursor
+	// Move cursor to page
 	reqs = append(reqs, Request{
 		Method:     ActSet,
 		Controller: boxController,
 		Attrs: map[string]interface{}{
-			"PageNumber": 1,
+			"PageNumber": page,
 		},
 	})
 
@@ -373,6 +596,106 @@ func (c *SMSClient) List(ctx context.Context, folder string) (*model.ListRespons
 	return result, nil
 }
 
+// Box retrieves the message totals of the specified folder.
+func (c *SMSClient) Box(ctx context.Context, folder string) (*model.BoxInfo, error) {
+	if folder == "" {
+		folder = "inbox"
+	}
+
+	var controller string
+	if folder == "inbox" {
+		controller = "LTE_SMS_RECVMSGBOX"
+	} else if folder == "sent" {
+		controller = "LTE_SMS_SENDMSGBOX"
+	} else {
+		return nil, fmt.Errorf("invalid folder: %s", folder)
+	}
+
+	reqs := []Request{
+		{
+			Method:     ActGet,
+			Controller: controller,
+			Attrs:      []string{"totalNumber", "amountPerPage", "pageNumber", "unreadMessages"},
+		},
+	}
+
+	resp, err := c.execute(ctx, reqs)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Error != 0 {
+		return nil, fmt.Errorf("router returned error code: %d", resp.Error)
+	}
+
+	result := &model.BoxInfo{}
+	for _, obj := range resp.Data {
+		if v, ok := obj["totalNumber"].(int); ok {
+			result.Total = v
+		}
+		if v, ok := obj["amountPerPage"].(int); ok {
+			result.PerPage = v
+		}
+		if v, ok := obj["pageNumber"].(int); ok {
+			result.Page = v
+		}
+		if v, ok := obj["unreadMessages"].(int); ok {
+			result.Unread = v
+		}
+	}
+	return result, nil
+}
+
+// Messages returns an iterator over all messages in the specified folder.
+// It walks every page until the folder is exhausted, skipping messages
+// seen on an earlier page in case new messages shift the pages.
+func (c *SMSClient) Messages(ctx context.Context, folder string) iter.Seq2[model.SMSMessage, error] {
+	return func(yield func(model.SMSMessage, error) bool) {
+		box, err := c.Box(ctx, folder)
+		if err != nil {
+			yield(model.SMSMessage{}, err)
+			return
+		}
+
+		seen := map[int]bool{}
+		for page := 1; page <= box.Pages(); page++ {
+			resp, err := c.ListPage(ctx, folder, page)
+			if err != nil {
+				yield(model.SMSMessage{}, err)
+				return
+			}
+			if resp.Error != 0 {
+				yield(model.SMSMessage{}, fmt.Errorf("router returned error code: %d", resp.Error))
+				return
+			}
+			if len(resp.Data) == 0 {
+				return
+			}
+
+			for _, msg := range resp.Data {
+				if seen[msg.Index] {
+					continue
+				}
+				seen[msg.Index] = true
+				if !yield(msg, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// ListAll retrieves all SMS messages from the specified folder.
+func (c *SMSClient) ListAll(ctx context.Context, folder string) (*model.ListResponse, error) {
+	result := &model.ListResponse{}
+	for msg, err := range c.Messages(ctx, folder) {
+		if err != nil {
+			return nil, err
+		}
+		result.Data = append(result.Data, msg)
+	}
+	return result, nil
+}
+
 // Read retrieves a specific SMS message.
 func (c *SMSClient) Read(ctx context.Context, folder string, index int) (*model.ReadResponse, error) {
 	if folder == "" {
@@ -457,6 +780,43 @@ func (c *SMSClient) Delete(ctx context.Context, folder string, index int) (*mode
 	return result, nil
 }
 
+// Find looks up a message by its index, walking every page of the folder.
+// It returns the message with its page and 1-based position on the page,
+// leaving the router cursor on that page, as Delete expects.
+func (c *SMSClient) Find(ctx context.Context, folder string, index int) (*model.SMSMessage, int, int, error) {
+	box, err := c.Box(ctx, folder)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	for page := 1; page <= box.Pages(); page++ {
+		resp, err := c.ListPage(ctx, folder, page)
+		if err != nil {
+			return nil, 0, 0, err
+		}
+		if resp.Error != 0 {
+			return nil, 0, 0, fmt.Errorf("router returned error code: %d", resp.Error)
+		}
+
+		for i, msg := range resp.Data {
+			if msg.Index == index {
+				return &msg, page, i + 1, nil
+			}
+		}
+	}
+
+	return nil, 0, 0, fmt.Errorf("%w: %d in %s", ErrNotFound, index, folder)
+}
+
+// DeleteByIndex removes the message with the given index from the folder.
+func (c *SMSClient) DeleteByIndex(ctx context.Context, folder string, index int) (*model.DeleteResponse, error) {
+	_, _, position, err := c.Find(ctx, folder, index)
+	if err != nil {
+		return nil, err
+	}
+	return c.Delete(ctx, folder, position)
+}
+
 // Send sends an SMS message.
 func (c *SMSClient) Send(ctx context.Context, number, message string) (*model.SendResponse, error) {
 	reqs := []Request{
@@ -516,5 +876,9 @@ func (c *SMSClient) rawToSMSMessage(obj map[string]interface{}, folder string) m
 		msg.Unread = v.(bool)
 	}
 
+	msg.Folder = folder
+	msg.Host = c.Host()
+	msg.Hash = msg.ContentHash()
+
 	return msg
 }
@@ -0,0 +1,112 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/titpetric/tp-link-cli/model"
+)
+
+// WAN_LTE_INTF_CFG holds the mobile data statistics and limit, at the
+// first stack level.
+const (
+	dataController = "WAN_LTE_INTF_CFG"
+	dataStack      = "2,0,0,0,0,0"
+)
+
+// DataUsage retrieves the mobile data statistics and limit.
+func (c *SMSClient) DataUsage(ctx context.Context) (*model.DataUsage, error) {
+	reqs := []Request{
+		{Method: ActGet, Controller: dataController, Stack: dataStack},
+	}
+
+	attrs, err := c.status(ctx, reqs)
+	if err != nil {
+		return nil, err
+	}
+
+	billingDay := 0
+	if attrString(attrs, "enablePaymentDay") == "1" {
+		billingDay = int(attrFloat(attrs, "paymentDay"))
+	}
+
+	return &model.DataUsage{
+		Period: attrUint(attrs, "curStatistics"),
+		Total:  attrUint(attrs, "totalStatistics"),
+		RX:     attrUint(attrs, "rxStatistics"),
+		TX:     attrUint(attrs, "txStatistics"),
+		RXRate: attrUint(attrs, "curRxSpeed"),
+		TXRate: attrUint(attrs, "curTxSpeed"),
+		DataLimit: model.DataLimit{
+			Enabled:      attrString(attrs, "enableDataLimit") == "1",
+			Limit:        attrUint(attrs, "limitation"),
+			AlertPercent: int(attrFloat(attrs, "warningPercent")),
+			BillingDay:   billingDay,
+		},
+	}, nil
+}
+
+// ResetDataUsage clears the data statistics, starting a new period.
+func (c *SMSClient) ResetDataUsage(ctx context.Context) error {
+	return c.setData(ctx, map[string]interface{}{
+		"clearStatistics": 1,
+	})
+}
+
+// SetDataLimit configures the data limit. The limit is validated first:
+// an enabled limit must be above zero, the alert percent within 0-100,
+// and the billing day within 0-31, where 0 disables the monthly reset.
+func (c *SMSClient) SetDataLimit(ctx context.Context, limit model.DataLimit) error {
+	if limit.Enabled && limit.Limit == 0 {
+		return fmt.Errorf("data limit must be above zero")
+	}
+	if limit.AlertPercent < 0 || limit.AlertPercent > 100 {
+		return fmt.Errorf("invalid alert percent: %d", limit.AlertPercent)
+	}
+	if limit.BillingDay < 0 || limit.BillingDay > 31 {
+		return fmt.Errorf("invalid billing day: %d", limit.BillingDay)
+	}
+
+	attrs := map[string]interface{}{
+		"enableDataLimit":  boolAttr(limit.Enabled),
+		"warningPercent":   limit.AlertPercent,
+		"enablePaymentDay": boolAttr(limit.BillingDay > 0),
+	}
+	if limit.Enabled {
+		attrs["limitation"] = strconv.FormatUint(limit.Limit, 10)
+	}
+	if limit.BillingDay > 0 {
+		attrs["paymentDay"] = limit.BillingDay
+	}
+	return c.setData(ctx, attrs)
+}
+
+func (c *SMSClient) setData(ctx context.Context, attrs map[string]interface{}) error {
+	reqs := []Request{
+		{Method: ActSet, Controller: dataController, Stack: dataStack, Attrs: attrs},
+	}
+
+	resp, err := c.execute(ctx, reqs)
+	if err != nil {
+		return err
+	}
+	if resp.Error != 0 {
+		return fmt.Errorf("router returned error code: %d", resp.Error)
+	}
+	return nil
+}
+
+// attrUint returns a byte count attribute of a response object, or zero.
+func attrUint(obj map[string]interface{}, name string) uint64 {
+	n, _ := strconv.ParseUint(attrString(obj, name), 10, 64)
+	return n
+}
+
+// boolAttr returns the 0 or 1 the router uses for a flag.
+func boolAttr(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,69 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/titpetric/tp-link-cli/model"
+)
+
+func TestDataUsage(t *testing.T) {
+	c, router := newTestClient(t)
+	router.SetStatus("WAN_LTE_INTF_CFG", map[string]string{
+		"curStatistics":    "150000000000",
+		"totalStatistics":  "900000000000",
+		"rxStatistics":     "800000000000",
+		"txStatistics":     "100000000000",
+		"curRxSpeed":       "125000",
+		"curTxSpeed":       "2500",
+		"enableDataLimit":  "1",
+		"limitation":       "200000000000",
+		"warningPercent":   "90",
+		"enablePaymentDay": "1",
+		"paymentDay":       "15",
+	})
+
+	usage, err := c.DataUsage(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, &model.DataUsage{
+		Period: 150000000000,
+		Total:  900000000000,
+		RX:     800000000000,
+		TX:     100000000000,
+		RXRate: 125000,
+		TXRate: 2500,
+		DataLimit: model.DataLimit{
+			Enabled:      true,
+			Limit:        200000000000,
+			AlertPercent: 90,
+			BillingDay:   15,
+		},
+	}, usage)
+	assert.Equal(t, uint64(50000000000), usage.Remaining())
+}
+
+func TestSetDataLimit(t *testing.T) {
+	c, router := newTestClient(t)
+	router.SetStatus("WAN_LTE_INTF_CFG", map[string]string{
+		"enableDataLimit": "0",
+		"paymentDay":      "1",
+	})
+	ctx := context.Background()
+
+	require.NoError(t, c.SetDataLimit(ctx, model.DataLimit{Enabled: true, Limit: 200 << 30, AlertPercent: 80}))
+	status := router.Status("WAN_LTE_INTF_CFG")
+	assert.Equal(t, "1", status["enableDataLimit"])
+	assert.Equal(t, "214748364800", status["limitation"])
+	assert.Equal(t, "80", status["warningPercent"])
+	assert.Equal(t, "0", status["enablePaymentDay"])
+
+	assert.Error(t, c.SetDataLimit(ctx, model.DataLimit{Enabled: true}))
+	assert.Error(t, c.SetDataLimit(ctx, model.DataLimit{AlertPercent: 101}))
+	assert.Error(t, c.SetDataLimit(ctx, model.DataLimit{BillingDay: 32}))
+
+	require.NoError(t, c.ResetDataUsage(ctx))
+	assert.Equal(t, "1", router.Status("WAN_LTE_INTF_CFG")["clearStatistics"])
+}
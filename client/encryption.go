@@ -148,6 +148,10 @@ func (a *AES) Decrypt(ciphertext string) (string, error) {
 		return "", err
 	}
 
+	if len(cipherBytes) == 0 || len(cipherBytes)%aes.BlockSize != 0 {
+		return "", fmt.Errorf("invalid ciphertext length: %d", len(cipherBytes))
+	}
+
 	block, err := aes.NewCipher(a.key)
 	if err != nil {
 		return "", err
@@ -159,6 +163,9 @@ func (a *AES) Decrypt(ciphertext string) (string, error) {
 
 	// Remove PKCS7 padding
 	padLen := int(plaintext[len(plaintext)-1])
+	if padLen == 0 || padLen > aes.BlockSize {
+		return "", fmt.Errorf("invalid padding")
+	}
 	plaintext = plaintext[:len(plaintext)-padLen]
 
 	return string(plaintext), nil
@@ -288,6 +295,11 @@ func (e *Encryption) SetSeq(seq int) {
 	e.seq = seq
 }
 
+// GetSeq returns the sequence number.
+func (e *Encryption) GetSeq() int {
+	return e.seq
+}
+
 // SetRSAKey configures the RSA public key.
 func (e *Encryption) SetRSAKey(nHex, eHex string) error {
 	rsa, err := NewRSAKey(nHex, eHex)
@@ -309,6 +321,11 @@ func (e *Encryption) GetAESKeyString() string {
 	return e.aesKeyStr
 }
 
+// GetAESKey returns the AES key and IV as numeric strings.
+func (e *Encryption) GetAESKey() (key, iv string) {
+	return e.aes.keyNumStr, e.aes.ivNumStr
+}
+
 // SetAESKey sets the AES key and IV from numeric strings.
 func (e *Encryption) SetAESKey(key, iv string) error {
 	e.aes.SetKeyFromNumeric(key, iv)
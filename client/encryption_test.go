@@ -79,3 +79,16 @@ func TestEncryptionSetAESKey(t *testing.T) {
 	assert.Contains(t, keyStr, "0123456789012345")
 	assert.Contains(t, keyStr, "5432109876543210")
 }
+
+func TestAESDecryptInvalid(t *testing.T) {
+	aes := NewAES()
+
+	_, err := aes.Decrypt("<html>login</html>")
+	assert.Error(t, err)
+
+	_, err = aes.Decrypt("")
+	assert.Error(t, err)
+
+	_, err = aes.Decrypt("YWJj")
+	assert.Error(t, err)
+}
@@ -0,0 +1,225 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/titpetric/tp-link-cli/model"
+)
+
+// LTE status controllers, read at the second stack level.
+const (
+	lteLinkController    = "WAN_LTE_LINK_CFG"
+	lteNetController     = "LTE_NET_STATUS"
+	lteProfileController = "LTE_PROF_STAT"
+	lteStack             = "2,1,0,0,0,0"
+)
+
+// networkTypes names the networkType values of WAN_LTE_LINK_CFG.
+var networkTypes = map[int]string{
+	0: "No Service",
+	1: "GSM",
+	2: "WCDMA",
+	3: "4G LTE",
+	4: "TD-SCDMA",
+	5: "CDMA 1x",
+	6: "CDMA 1x Ev-Do",
+	7: "4G+ LTE",
+}
+
+// connectStatuses names the connectStatus values of WAN_LTE_LINK_CFG.
+var connectStatuses = map[int]string{
+	0: "disabled",
+	1: "disconnected",
+	2: "connecting",
+	3: "disconnecting",
+	4: "connected",
+}
+
+// simStatuses names the simStatus values of WAN_LTE_LINK_CFG.
+var simStatuses = map[int]string{
+	0: "no SIM",
+	1: "unknown",
+	2: "PIN required",
+	3: "PUK required",
+	4: "unlocked",
+	5: "invalid SIM",
+}
+
+// Signal retrieves the LTE signal quality.
+func (c *SMSClient) Signal(ctx context.Context) (*model.Signal, error) {
+	reqs := []Request{
+		{
+			Method:     ActGet,
+			Controller: lteNetController,
+			Stack:      lteStack,
+		},
+	}
+
+	attrs, err := c.status(ctx, reqs)
+	if err != nil {
+		return nil, err
+	}
+	signal := signalFromAttrs(attrs)
+	return &signal, nil
+}
+
+// LTEStatus retrieves the LTE connection state, operator and signal.
+// Attributes the router does not report are left empty.
+func (c *SMSClient) LTEStatus(ctx context.Context) (*model.LTEStatus, error) {
+	reqs := []Request{
+		{Method: ActGet, Controller: lteLinkController, Stack: lteStack},
+		{Method: ActGet, Controller: lteNetController, Stack: lteStack},
+		{Method: ActGet, Controller: lteProfileController, Stack: lteStack},
+	}
+
+	attrs, err := c.status(ctx, reqs)
+	if err != nil {
+		return nil, err
+	}
+
+	connectStatus := int(attrFloat(attrs, "connectStatus"))
+	result := &model.LTEStatus{
+		Signal:      signalFromAttrs(attrs),
+		Operator:    attrString(attrs, "ispName"),
+		NetworkType: lookupName(networkTypes, attrs, "networkType"),
+		Connection:  lookupName(connectStatuses, attrs, "connectStatus"),
+		Connected:   connectStatus == 4,
+		Enabled:     attrString(attrs, "enable") == "1",
+		Roaming:     attrString(attrs, "roamingStatus") == "1",
+		SIMStatus:   lookupName(simStatuses, attrs, "simStatus"),
+	}
+	if result.Connected {
+		result.IP = attrString(attrs, "ipv4")
+	}
+	if result.Operator == "" {
+		result.Operator = attrString(attrs, "spn")
+	}
+	return result, nil
+}
+
+// SetLTEEnabled enables or disables the LTE data connection. The router
+// connects or disconnects in the background, see WaitLTE.
+func (c *SMSClient) SetLTEEnabled(ctx context.Context, enabled bool) error {
+	reqs := []Request{
+		{
+			Method:     ActSet,
+			Controller: lteLinkController,
+			Stack:      lteStack,
+			Attrs: map[string]interface{}{
+				"enable": boolAttr(enabled),
+			},
+		},
+	}
+
+	resp, err := c.execute(ctx, reqs)
+	if err != nil {
+		return err
+	}
+	if resp.Error != 0 {
+		return fmt.Errorf("router returned error code: %d", resp.Error)
+	}
+	return nil
+}
+
+// WaitLTE polls the LTE status every interval until the connection is
+// connected, or disconnected or disabled if connected is false. It
+// returns the last status, also when the context is done first.
+func (c *SMSClient) WaitLTE(ctx context.Context, connected bool, interval time.Duration) (*model.LTEStatus, error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		status, err := c.LTEStatus(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		settled := status.Connected
+		if !connected {
+			settled = status.Connection == connectStatuses[0] || status.Connection == connectStatuses[1]
+		}
+		if settled {
+			return status, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return status, fmt.Errorf("LTE connection is %s: %w", status.Connection, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// status sends the requests and returns the attributes of all response
+// objects merged, as status controllers have distinct attribute names.
+func (c *SMSClient) status(ctx context.Context, reqs []Request) (map[string]interface{}, error) {
+	resp, err := c.execute(ctx, reqs)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Error != 0 {
+		return nil, fmt.Errorf("router returned error code: %d", resp.Error)
+	}
+	if len(resp.Data) == 0 {
+		return nil, fmt.Errorf("router returned no status")
+	}
+
+	attrs := map[string]interface{}{}
+	for _, obj := range resp.Data {
+		for k, v := range obj {
+			attrs[k] = v
+		}
+	}
+	return attrs, nil
+}
+
+func signalFromAttrs(attrs map[string]interface{}) model.Signal {
+	return model.Signal{
+		Level:   int(attrFloat(attrs, "sigLevel")),
+		RSRP:    attrFloat(attrs, "rfInfoRsrp"),
+		RSRQ:    attrFloat(attrs, "rfInfoRsrq"),
+		SNR:     attrFloat(attrs, "rfInfoSnr"),
+		RSSI:    attrFloat(attrs, "rfInfoRssi"),
+		Band:    attrString(attrs, "rfInfoBand"),
+		Channel: attrString(attrs, "rfInfoChannel"),
+		CellID:  attrString(attrs, "cellId"),
+	}
+}
+
+// lookupName returns the name of a numeric attribute, or the raw value
+// if it has no name.
+func lookupName(names map[int]string, attrs map[string]interface{}, name string) string {
+	value := attrString(attrs, name)
+	if n, err := strconv.Atoi(value); err == nil {
+		if s, ok := names[n]; ok {
+			return s
+		}
+	}
+	return value
+}
+
+// attrString returns an attribute of a response object as a string.
+func attrString(obj map[string]interface{}, name string) string {
+	switch v := obj[name].(type) {
+	case string:
+		return v
+	case int:
+		return strconv.Itoa(v)
+	}
+	return ""
+}
+
+// attrFloat returns a numeric attribute of a response object, or zero.
+func attrFloat(obj map[string]interface{}, name string) float64 {
+	switch v := obj[name].(type) {
+	case int:
+		return float64(v)
+	case string:
+		f, _ := strconv.ParseFloat(v, 64)
+		return f
+	}
+	return 0
+}
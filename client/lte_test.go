@@ -0,0 +1,111 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/titpetric/tp-link-cli/fakerouter"
+	"github.com/titpetric/tp-link-cli/model"
+)
+
+func setLTEStatus(router *fakerouter.Router) {
+	router.SetStatus("WAN_LTE_LINK_CFG", map[string]string{
+		"enable":        "1",
+		"connectStatus": "4",
+		"networkType":   "3",
+		"roamingStatus": "0",
+		"simStatus":     "4",
+	})
+	router.SetStatus("LTE_NET_STATUS", map[string]string{
+		"sigLevel":      "4",
+		"rfInfoRsrp":    "-95",
+		"rfInfoRsrq":    "-11",
+		"rfInfoSnr":     "12.5",
+		"rfInfoRssi":    "-67",
+		"rfInfoBand":    "3",
+		"rfInfoChannel": "1300",
+		"cellId":        "0A1B2C",
+	})
+	router.SetStatus("LTE_PROF_STAT", map[string]string{
+		"spn":     "",
+		"ispName": "A1 SI",
+	})
+}
+
+func TestSignal(t *testing.T) {
+	c, router := newTestClient(t)
+	setLTEStatus(router)
+
+	signal, err := c.Signal(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 4, signal.Level)
+	assert.Equal(t, -95.0, signal.RSRP)
+	assert.Equal(t, 12.5, signal.SNR)
+}
+
+func TestLTEStatus(t *testing.T) {
+	c, router := newTestClient(t)
+	setLTEStatus(router)
+
+	status, err := c.LTEStatus(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, &model.LTEStatus{
+		Signal: model.Signal{
+			Level:   4,
+			RSRP:    -95,
+			RSRQ:    -11,
+			SNR:     12.5,
+			RSSI:    -67,
+			Band:    "3",
+			Channel: "1300",
+			CellID:  "0A1B2C",
+		},
+		Operator:    "A1 SI",
+		NetworkType: "4G LTE",
+		Connection:  "connected",
+		Connected:   true,
+		Enabled:     true,
+		SIMStatus:   "unlocked",
+	}, status)
+}
+
+func TestLTEStatusUnsupported(t *testing.T) {
+	c, _ := newTestClient(t)
+
+	_, err := c.LTEStatus(context.Background())
+	assert.ErrorContains(t, err, "error code")
+}
+
+func TestLTEConnect(t *testing.T) {
+	c, router := newTestClient(t)
+	setLTEStatus(router)
+	ctx := context.Background()
+
+	require.NoError(t, c.SetLTEEnabled(ctx, false))
+	status, err := c.WaitLTE(ctx, false, 10*time.Millisecond)
+	require.NoError(t, err)
+	assert.Equal(t, "disabled", status.Connection)
+	assert.Empty(t, status.IP)
+
+	require.NoError(t, c.SetLTEEnabled(ctx, true))
+	status, err = c.WaitLTE(ctx, true, 10*time.Millisecond)
+	require.NoError(t, err)
+	assert.True(t, status.Connected)
+	assert.Equal(t, "100.64.0.1", status.IP)
+	assert.Equal(t, 1, router.LTEConnects())
+}
+
+func TestWaitLTETimeout(t *testing.T) {
+	c, router := newTestClient(t)
+	setLTEStatus(router)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := c.WaitLTE(ctx, false, 10*time.Millisecond)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
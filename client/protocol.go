@@ -15,6 +15,8 @@ const (
 	ActGet = 1
 	// ActSet is the Set action.
 	ActSet = 2
+	// ActAdd is the Add action.
+	ActAdd = 3
 	// ActDel is the Delete action.
 	ActDel = 4
 	// ActGL is the Get List action.
@@ -25,6 +27,34 @@ const (
 	ActCGI = 8
 )
 
+// methodNames maps the action names used on the command line to methods.
+var methodNames = map[string]int{
+	"get": ActGet,
+	"set": ActSet,
+	"add": ActAdd,
+	"del": ActDel,
+	"gl":  ActGL,
+	"gs":  ActGS,
+	"cgi": ActCGI,
+}
+
+// ParseMethod returns the action method for a name (get, set, add, del,
+// gl, gs or cgi) or a method number.
+func ParseMethod(name string) (int, error) {
+	if method, ok := methodNames[strings.ToLower(name)]; ok {
+		return method, nil
+	}
+	method, err := strconv.Atoi(name)
+	if err == nil {
+		for _, m := range methodNames {
+			if m == method {
+				return method, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("invalid method: %s", name)
+}
+
 // Request represents a router protocol request.
 type Request struct {
 	Method     int
@@ -35,12 +65,16 @@ type Request struct {
 
 // Response represents a router protocol response.
 type Response struct {
-	Error int
-	Data  []map[string]interface{}
+	Error int                      `json:"error"`
+	Data  []map[string]interface{} `json:"data"`
 }
 
 // Protocol handles encoding and decoding of TP-Link router protocol messages.
-type Protocol struct{}
+type Protocol struct {
+	// Location is the time zone of the router clock, which reports
+	// times without a zone. Nil is time.Local.
+	Location *time.Location
+}
 
 // NewProtocol creates a new Protocol handler.
 func NewProtocol() *Protocol {
@@ -168,8 +202,12 @@ func (p *Protocol) FromDataFrame(frame string) Response {
 // PrettifyResponse converts raw response data to proper types.
 func (p *Protocol) PrettifyResponse(resp Response) Response {
 	intAttrs := map[string]bool{
-		"index":      true,
-		"sendResult": true,
+		"index":          true,
+		"sendResult":     true,
+		"totalNumber":    true,
+		"amountPerPage":  true,
+		"pageNumber":     true,
+		"unreadMessages": true,
 	}
 	boolAttrs := map[string]bool{
 		"unread": true,
@@ -178,6 +216,15 @@ func (p *Protocol) PrettifyResponse(resp Response) Response {
 		"receivedTime": true,
 		"sendTime":     true,
 	}
+	textAttrs := map[string]bool{
+		"content":  true,
+		"response": true,
+	}
+
+	loc := p.Location
+	if loc == nil {
+		loc = time.Local
+	}
 
 	for i, obj := range resp.Data {
 		for key, val := range obj {
@@ -190,10 +237,10 @@ func (p *Protocol) PrettifyResponse(resp Response) Response {
 					obj[key] = v > 0
 				}
 			} else if dateAttrs[key] {
-				if t, err := time.Parse("2006-01-02 15:04:05", val.(string)); err == nil {
+				if t, err := time.ParseInLocation("2006-01-02 15:04:05", val.(string), loc); err == nil {
 					obj[key] = t
 				}
-			} else if key == "content" {
+			} else if textAttrs[key] {
 				valStr := val.(string)
 				valStr = strings.ReplaceAll(valStr, "\u0012", "\n")
 				obj[key] = valStr
@@ -3,6 +3,7 @@ package client
 import (
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -105,6 +106,22 @@ func TestPrettifyResponse(t *testing.T) {
 	assert.True(t, strings.Contains(prettified.Data[0]["content"].(string), "\n"))
 }
 
+func TestPrettifyResponseLocation(t *testing.T) {
+	proto := NewProtocol()
+	resp := func() Response {
+		return Response{Data: []map[string]interface{}{{"receivedTime": "2026-07-01 12:30:00"}}}
+	}
+
+	// The router clock has no zone, it is the local time by default
+	prettified := proto.PrettifyResponse(resp())
+	assert.Equal(t, time.Date(2026, 7, 1, 12, 30, 0, 0, time.Local), prettified.Data[0]["receivedTime"])
+
+	proto.Location = time.FixedZone("CEST", 2*60*60)
+	prettified = proto.PrettifyResponse(resp())
+	got := prettified.Data[0]["receivedTime"].(time.Time)
+	assert.Equal(t, "Wed, 01 Jul 2026 12:30:00 +0200", got.Format(time.RFC1123Z))
+}
+
 func TestMakeDataFrameWithStack(t *testing.T) {
 	proto := NewProtocol()
 
@@ -130,3 +147,16 @@ func TestToKVWithNewlineReplacement(t *testing.T) {
 	result := proto.toKV(attrs)
 	assert.Contains(t, result, "line1\u0012line2\u0012line3")
 }
+
+func TestParseMethod(t *testing.T) {
+	for name, want := range map[string]int{"get": ActGet, "add": ActAdd, "GL": ActGL, "cgi": ActCGI, "6": ActGS} {
+		method, err := ParseMethod(name)
+		assert.NoError(t, err, name)
+		assert.Equal(t, want, method, name)
+	}
+
+	for _, name := range []string{"", "put", "7"} {
+		_, err := ParseMethod(name)
+		assert.Error(t, err, name)
+	}
+}
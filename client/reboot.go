@@ -0,0 +1,67 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// ErrRebootRejected is returned when the router answers a reboot with
+// an error code, so it is not rebooting. Other errors may come from the
+// router restarting before its response is complete.
+var ErrRebootRejected = errors.New("router rejected the reboot")
+
+// Reboot restarts the router. The session ends with the reboot, the
+// client logs in again on the next request.
+func (c *SMSClient) Reboot(ctx context.Context) error {
+	reqs := []Request{
+		{
+			Method:     ActCGI,
+			Controller: "/cgi/reboot",
+		},
+	}
+
+	resp, err := c.execute(ctx, reqs)
+	if err == nil && resp.Error != 0 {
+		return fmt.Errorf("%w: error code %d", ErrRebootRejected, resp.Error)
+	}
+
+	// The session ends with the reboot, which may also cut the response
+	// short, so it is dropped on other errors too
+	if c.cache != nil {
+		c.cache.Delete(c.baseURL, c.username)
+	}
+	c.SessionID = ""
+	c.TokenID = ""
+	return err
+}
+
+// Ping checks that the router web interface is up, by fetching the
+// encryption parameters from /cgi/getParm. It does not log in.
+func (c *SMSClient) Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/cgi/getParm", nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Referer", c.baseURL)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("getParm returned status %d", resp.StatusCode)
+	}
+	if _, _, _, err := ParseEncryptionParams(string(body)); err != nil {
+		return fmt.Errorf("failed to parse encryption params: %w", err)
+	}
+	return nil
+}
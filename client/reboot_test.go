@@ -0,0 +1,29 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReboot(t *testing.T) {
+	c, router := newTestClient(t)
+	router.RebootDelay = 200 * time.Millisecond
+	ctx := context.Background()
+
+	require.NoError(t, c.Ping(ctx))
+	require.NoError(t, c.Reboot(ctx))
+	assert.Equal(t, 1, router.Reboots())
+	assert.Empty(t, c.TokenID)
+
+	assert.Error(t, c.Ping(ctx))
+	require.Eventually(t, func() bool { return c.Ping(ctx) == nil }, 5*time.Second, 20*time.Millisecond)
+
+	// The client logs in again after the reboot
+	_, err := c.Box(ctx, "inbox")
+	require.NoError(t, err)
+	assert.Equal(t, 2, router.Logins())
+}
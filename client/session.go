@@ -0,0 +1,95 @@
+package client
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Session holds the state of an authenticated router session.
+type Session struct {
+	Host        string    `json:"host"`
+	Username    string    `json:"username"`
+	SessionID   string    `json:"sessionID"`
+	TokenID     string    `json:"tokenID"`
+	AESKey      string    `json:"aesKey"`
+	AESIV       string    `json:"aesIV"`
+	Seq         int       `json:"seq"`
+	RSAModulus  string    `json:"rsaModulus"`
+	RSAExponent string    `json:"rsaExponent"`
+	Created     time.Time `json:"created"`
+}
+
+// SessionCache stores sessions on disk, keyed by host and username.
+type SessionCache struct {
+	Dir string
+}
+
+// NewSessionCache creates a session cache in dir. If dir is empty,
+// a tp-link-cli folder in the user cache directory is used.
+func NewSessionCache(dir string) *SessionCache {
+	if dir == "" {
+		base, err := os.UserCacheDir()
+		if err != nil {
+			base = os.TempDir()
+		}
+		dir = filepath.Join(base, "tp-link-cli")
+	}
+	return &SessionCache{Dir: dir}
+}
+
+// Load returns the cached session, or nil if none is stored.
+func (s *SessionCache) Load(host, username string) (*Session, error) {
+	data, err := os.ReadFile(s.path(host, username))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var session Session
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, fmt.Errorf("failed to decode session cache: %w", err)
+	}
+	return &session, nil
+}
+
+// Save writes the session to the cache.
+func (s *SessionCache) Save(session *Session) error {
+	if err := os.MkdirAll(s.Dir, 0o700); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(session, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	filename := s.path(session.Host, session.Username)
+	tmp := filename + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filename)
+}
+
+// Delete removes the cached session, if any.
+func (s *SessionCache) Delete(host, username string) error {
+	err := os.Remove(s.path(host, username))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// path returns the cache file for a host and username.
+func (s *SessionCache) path(host, username string) string {
+	sum := sha256.Sum256([]byte(host + "\n" + username))
+	return filepath.Join(s.Dir, "session-"+hex.EncodeToString(sum[:8])+".json")
+}
@@ -0,0 +1,119 @@
+package client
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/titpetric/tp-link-cli/fakerouter"
+)
+
+func TestSessionCacheRoundTrip(t *testing.T) {
+	cache := NewSessionCache(t.TempDir())
+
+	session, err := cache.Load("http://192.168.1.1", "admin")
+	require.NoError(t, err)
+	assert.Nil(t, session)
+
+	want := &Session{
+		Host:      "http://192.168.1.1",
+		Username:  "admin",
+		SessionID: "abc",
+		TokenID:   "def",
+		AESKey:    "0123456789012345",
+		AESIV:     "5432109876543210",
+		Seq:       42,
+	}
+	require.NoError(t, cache.Save(want))
+
+	info, err := os.Stat(cache.path(want.Host, want.Username))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	got, err := cache.Load(want.Host, want.Username)
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+
+	other, err := cache.Load(want.Host, "user")
+	require.NoError(t, err)
+	assert.Nil(t, other)
+
+	require.NoError(t, cache.Delete(want.Host, want.Username))
+	got, err = cache.Load(want.Host, want.Username)
+	require.NoError(t, err)
+	assert.Nil(t, got)
+}
+
+func newCachedClient(t *testing.T, router *fakerouter.Router, cache *SessionCache) *SMSClient {
+	t.Helper()
+
+	c, err := NewSMSClient(&Options{
+		Auth:         "admin:secret",
+		Host:         router.URL(),
+		SessionCache: cache,
+	})
+	require.NoError(t, err)
+	return c
+}
+
+func TestSessionCacheReuse(t *testing.T) {
+	router := fakerouter.New("admin", "secret")
+	defer router.Close()
+	router.Receive("+38640111222", "hello", time.Now())
+
+	cache := NewSessionCache(t.TempDir())
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		resp, err := newCachedClient(t, router, cache).List(ctx, "inbox")
+		require.NoError(t, err)
+		require.Len(t, resp.Data, 1)
+	}
+
+	assert.Equal(t, 1, router.Logins())
+}
+
+func TestSessionCacheRefresh(t *testing.T) {
+	router := fakerouter.New("admin", "secret")
+	defer router.Close()
+	router.Receive("+38640111222", "hello", time.Now())
+
+	cache := NewSessionCache(t.TempDir())
+	ctx := context.Background()
+
+	_, err := newCachedClient(t, router, cache).List(ctx, "inbox")
+	require.NoError(t, err)
+
+	router.ExpireSession()
+
+	resp, err := newCachedClient(t, router, cache).List(ctx, "inbox")
+	require.NoError(t, err)
+	require.Len(t, resp.Data, 1)
+	assert.Equal(t, 2, router.Logins())
+
+	session, err := cache.Load(router.URL(), "admin")
+	require.NoError(t, err)
+	require.NotNil(t, session)
+}
+
+func TestSessionCacheLogout(t *testing.T) {
+	router := fakerouter.New("admin", "secret")
+	defer router.Close()
+
+	cache := NewSessionCache(t.TempDir())
+	ctx := context.Background()
+
+	require.NoError(t, newCachedClient(t, router, cache).Connect(ctx))
+	require.NoError(t, newCachedClient(t, router, cache).Logout(ctx))
+
+	assert.False(t, router.LoggedIn())
+	assert.Equal(t, 1, router.Logins())
+
+	session, err := cache.Load(router.URL(), "admin")
+	require.NoError(t, err)
+	assert.Nil(t, session)
+}
@@ -0,0 +1,113 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/titpetric/tp-link-cli/model"
+)
+
+// simController holds the SIM identity and PIN lock.
+const simController = "LTE_SIMLOCK"
+
+// Actions of a SIM lock set.
+const (
+	simActionUnlock     = 1
+	simActionEnablePIN  = 2
+	simActionDisablePIN = 3
+	simActionChangePIN  = 4
+)
+
+// pinRegex matches a SIM PIN, 4 to 8 digits.
+var pinRegex = regexp.MustCompile(`^[0-9]{4,8}$`)
+
+// SIMInfo retrieves the SIM state, identity and PIN lock.
+func (c *SMSClient) SIMInfo(ctx context.Context) (*model.SIMInfo, error) {
+	reqs := []Request{
+		{Method: ActGet, Controller: lteLinkController, Stack: lteStack, Attrs: []string{"simStatus"}},
+		{Method: ActGet, Controller: simController, Stack: lteStack},
+	}
+
+	attrs, err := c.status(ctx, reqs)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.SIMInfo{
+		State:       lookupName(simStatuses, attrs, "simStatus"),
+		IMSI:        attrString(attrs, "imsi"),
+		ICCID:       attrString(attrs, "iccid"),
+		PINEnabled:  attrString(attrs, "pinEnable") == "1",
+		PINAttempts: int(attrFloat(attrs, "pinRemaining")),
+		PUKAttempts: int(attrFloat(attrs, "pukRemaining")),
+		PUKRequired: attrString(attrs, "simStatus") == "3",
+	}, nil
+}
+
+// UnlockSIM enters the PIN of a locked SIM.
+func (c *SMSClient) UnlockSIM(ctx context.Context, pin string) error {
+	return c.simAction(ctx, simActionUnlock, pin, "")
+}
+
+// SetSIMPINEnabled enables or disables the PIN lock, which takes the
+// current PIN.
+func (c *SMSClient) SetSIMPINEnabled(ctx context.Context, enabled bool, pin string) error {
+	action := simActionDisablePIN
+	if enabled {
+		action = simActionEnablePIN
+	}
+	return c.simAction(ctx, action, pin, "")
+}
+
+// ChangeSIMPIN changes the PIN, which must be enabled. The lock is
+// checked first, as the router rejects the change like a wrong PIN.
+func (c *SMSClient) ChangeSIMPIN(ctx context.Context, pin, newPIN string) error {
+	if !pinRegex.MatchString(newPIN) {
+		return fmt.Errorf("new PIN must be 4 to 8 digits")
+	}
+
+	info, err := c.SIMInfo(ctx)
+	if err != nil {
+		return err
+	}
+	if !info.PINEnabled {
+		return fmt.Errorf("PIN lock is disabled, enable it before changing the PIN")
+	}
+	return c.simAction(ctx, simActionChangePIN, pin, newPIN)
+}
+
+// simAction sends a SIM lock action. A rejected PIN is reported with the
+// attempts left, as the SIM needs the PUK once they run out.
+func (c *SMSClient) simAction(ctx context.Context, action int, pin, newPIN string) error {
+	if !pinRegex.MatchString(pin) {
+		return fmt.Errorf("PIN must be 4 to 8 digits")
+	}
+
+	attrs := map[string]interface{}{
+		"action": action,
+		"pin":    pin,
+	}
+	if newPIN != "" {
+		attrs["newPin"] = newPIN
+	}
+
+	resp, err := c.execute(ctx, []Request{
+		{Method: ActSet, Controller: simController, Stack: lteStack, Attrs: attrs},
+	})
+	if err != nil {
+		return err
+	}
+	if resp.Error == 0 {
+		return nil
+	}
+
+	info, err := c.SIMInfo(ctx)
+	if err != nil {
+		return fmt.Errorf("router returned error code: %d", resp.Error)
+	}
+	if info.PUKRequired {
+		return fmt.Errorf("PIN rejected, the SIM requires the PUK")
+	}
+	return fmt.Errorf("PIN rejected, %d attempts left", info.PINAttempts)
+}
@@ -0,0 +1,76 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/titpetric/tp-link-cli/fakerouter"
+	"github.com/titpetric/tp-link-cli/model"
+)
+
+func TestSIMInfo(t *testing.T) {
+	c, router := newTestClient(t)
+	router.SetSIM(fakerouter.SIM{IMSI: "293400123456789", ICCID: "8938600000000000001", PIN: "1234", PINEnabled: true})
+
+	info, err := c.SIMInfo(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, &model.SIMInfo{
+		State:       "PIN required",
+		IMSI:        "293400123456789",
+		ICCID:       "8938600000000000001",
+		PINEnabled:  true,
+		PINAttempts: 3,
+		PUKAttempts: 10,
+	}, info)
+}
+
+func TestUnlockSIM(t *testing.T) {
+	c, router := newTestClient(t)
+	router.SetSIM(fakerouter.SIM{PIN: "1234", PINEnabled: true})
+	ctx := context.Background()
+
+	assert.ErrorContains(t, c.UnlockSIM(ctx, "12"), "4 to 8 digits")
+	assert.ErrorContains(t, c.UnlockSIM(ctx, "0000"), "2 attempts left")
+
+	require.NoError(t, c.UnlockSIM(ctx, "1234"))
+	info, err := c.SIMInfo(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "unlocked", info.State)
+	assert.Equal(t, 3, info.PINAttempts)
+}
+
+func TestUnlockSIMPUKRequired(t *testing.T) {
+	c, router := newTestClient(t)
+	router.SetSIM(fakerouter.SIM{PIN: "1234", PINEnabled: true})
+	ctx := context.Background()
+
+	assert.Error(t, c.UnlockSIM(ctx, "0000"))
+	assert.Error(t, c.UnlockSIM(ctx, "0000"))
+	assert.ErrorContains(t, c.UnlockSIM(ctx, "0000"), "requires the PUK")
+
+	info, err := c.SIMInfo(ctx)
+	require.NoError(t, err)
+	assert.True(t, info.PUKRequired)
+	assert.Equal(t, "PUK required", info.State)
+}
+
+func TestSIMPIN(t *testing.T) {
+	c, router := newTestClient(t)
+	router.SetSIM(fakerouter.SIM{PIN: "1234"})
+	ctx := context.Background()
+
+	assert.ErrorContains(t, c.ChangeSIMPIN(ctx, "1234", "5678"), "PIN lock is disabled")
+
+	require.NoError(t, c.SetSIMPINEnabled(ctx, true, "1234"))
+	assert.True(t, router.SIM().PINEnabled)
+
+	assert.ErrorContains(t, c.ChangeSIMPIN(ctx, "1234", "56"), "4 to 8 digits")
+	require.NoError(t, c.ChangeSIMPIN(ctx, "1234", "5678"))
+	assert.Equal(t, "5678", router.SIM().PIN)
+
+	require.NoError(t, c.SetSIMPINEnabled(ctx, false, "5678"))
+	assert.False(t, router.SIM().PINEnabled)
+}
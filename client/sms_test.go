@@ -0,0 +1,256 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/titpetric/tp-link-cli/fakerouter"
+)
+
+func newTestClient(t *testing.T) (*SMSClient, *fakerouter.Router) {
+	t.Helper()
+
+	router := fakerouter.New("admin", "secret")
+	t.Cleanup(router.Close)
+
+	c, err := NewSMSClient(&Options{
+		Auth: "admin:secret",
+		Host: router.URL(),
+	})
+	require.NoError(t, err)
+	return c, router
+}
+
+func TestConnect(t *testing.T) {
+	c, _ := newTestClient(t)
+
+	err := c.Connect(context.Background())
+	require.NoError(t, err)
+	assert.NotEmpty(t, c.SessionID)
+	assert.NotEmpty(t, c.TokenID)
+}
+
+func TestConnectBadCredentials(t *testing.T) {
+	router := fakerouter.New("admin", "secret")
+	defer router.Close()
+
+	c, err := NewSMSClient(&Options{
+		Auth: "admin:wrong",
+		Host: router.URL(),
+	})
+	require.NoError(t, err)
+
+	err = c.Connect(context.Background())
+	assert.Error(t, err)
+	assert.Empty(t, c.TokenID)
+}
+
+func TestList(t *testing.T) {
+	c, router := newTestClient(t)
+	now := time.Now()
+	router.Receive("+38640111222", "first", now.Add(-time.Hour))
+	router.Receive("+38640333444", "second\nline", now)
+
+	resp, err := c.List(context.Background(), "inbox")
+	require.NoError(t, err)
+	assert.Equal(t, 0, resp.Error)
+	require.Len(t, resp.Data, 2)
+
+	assert.Equal(t, "+38640333444", resp.Data[0].From)
+	assert.Equal(t, "second\nline", resp.Data[0].Content)
+	assert.True(t, resp.Data[0].Unread)
+	assert.True(t, now.Truncate(time.Second).Equal(resp.Data[0].RecvTime))
+	assert.Equal(t, time.Local, resp.Data[0].RecvTime.Location())
+	assert.Equal(t, "first", resp.Data[1].Content)
+
+	assert.Equal(t, "inbox", resp.Data[0].Folder)
+	assert.Equal(t, c.Host(), resp.Data[0].Host)
+	assert.Equal(t, resp.Data[0].ContentHash(), resp.Data[0].Hash)
+	assert.NotEqual(t, resp.Data[0].Hash, resp.Data[1].Hash)
+}
+
+func TestListInvalidFolder(t *testing.T) {
+	c, _ := newTestClient(t)
+
+	_, err := c.List(context.Background(), "drafts")
+	assert.Error(t, err)
+}
+
+func TestRead(t *testing.T) {
+	c, router := newTestClient(t)
+	index := router.Receive("+38640111222", "hello", time.Now())
+
+	resp, err := c.Read(context.Background(), "inbox", 1)
+	require.NoError(t, err)
+	require.Len(t, resp.Data, 1)
+	assert.Equal(t, index, resp.Data[0].Index)
+	assert.Equal(t, "hello", resp.Data[0].Content)
+}
+
+func TestDelete(t *testing.T) {
+	c, router := newTestClient(t)
+	now := time.Now()
+	router.Receive("+38640111222", "older", now.Add(-time.Minute))
+	router.Receive("+38640111222", "newer", now)
+
+	resp, err := c.Delete(context.Background(), "inbox", 1)
+	require.NoError(t, err)
+	assert.Equal(t, 0, resp.Error)
+
+	inbox := router.Inbox()
+	require.Len(t, inbox, 1)
+	assert.Equal(t, "older", inbox[0].Content)
+}
+
+func TestSend(t *testing.T) {
+	c, router := newTestClient(t)
+
+	resp, err := c.Send(context.Background(), "13909", "brzina")
+	require.NoError(t, err)
+	assert.Equal(t, 0, resp.Error)
+
+	sent := router.Sent()
+	require.Len(t, sent, 1)
+	assert.Equal(t, "13909", sent[0].To)
+	assert.Equal(t, "brzina", sent[0].Content)
+
+	list, err := c.List(context.Background(), "sent")
+	require.NoError(t, err)
+	require.Len(t, list.Data, 1)
+	assert.Equal(t, "13909", list.Data[0].To)
+}
+
+func TestSessionExpiredReconnect(t *testing.T) {
+	c, router := newTestClient(t)
+	router.Receive("+38640111222", "hello", time.Now())
+	ctx := context.Background()
+
+	_, err := c.List(ctx, "inbox")
+	require.NoError(t, err)
+
+	router.ExpireSession()
+
+	resp, err := c.List(ctx, "inbox")
+	require.NoError(t, err)
+	require.Len(t, resp.Data, 1)
+	assert.Equal(t, 2, router.Logins())
+}
+
+func TestCorruptResponseNotReplayed(t *testing.T) {
+	c, router := newTestClient(t)
+	ctx := context.Background()
+	require.NoError(t, c.Connect(ctx))
+
+	// The SMS is sent, but the response can't be read
+	router.CorruptNextResponse()
+	_, err := c.Send(ctx, "13909", "brzina")
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, ErrSessionExpired)
+
+	assert.Len(t, router.Sent(), 1)
+	assert.Equal(t, 1, router.Logins())
+}
+
+func TestLogout(t *testing.T) {
+	c, router := newTestClient(t)
+	ctx := context.Background()
+
+	require.NoError(t, c.Connect(ctx))
+	assert.True(t, router.LoggedIn())
+
+	require.NoError(t, c.Logout(ctx))
+	assert.False(t, router.LoggedIn())
+	assert.Empty(t, c.TokenID)
+}
+
+func TestPagination(t *testing.T) {
+	c, router := newTestClient(t)
+	ctx := context.Background()
+
+	now := time.Now()
+	for i := 0; i < 20; i++ {
+		router.Receive("+38640111222", "message", now.Add(time.Duration(i)*time.Minute))
+	}
+
+	box, err := c.Box(ctx, "inbox")
+	require.NoError(t, err)
+	assert.Equal(t, 20, box.Total)
+	assert.Equal(t, 8, box.PerPage)
+	assert.Equal(t, 20, box.Unread)
+	assert.Equal(t, 3, box.Pages())
+
+	page, err := c.ListPage(ctx, "inbox", 3)
+	require.NoError(t, err)
+	require.Len(t, page.Data, 4)
+	assert.Equal(t, 1, page.Data[3].Index)
+
+	all, err := c.ListAll(ctx, "inbox")
+	require.NoError(t, err)
+	require.Len(t, all.Data, 20)
+	for i, msg := range all.Data {
+		assert.Equal(t, 20-i, msg.Index)
+	}
+
+	_, err = c.ListPage(ctx, "inbox", 0)
+	assert.Error(t, err)
+}
+
+func TestDeleteByIndex(t *testing.T) {
+	c, router := newTestClient(t)
+	ctx := context.Background()
+
+	now := time.Now()
+	for i := 0; i < 12; i++ {
+		router.Receive("+38640111222", "message", now.Add(time.Duration(i)*time.Minute))
+	}
+
+	msg, page, position, err := c.Find(ctx, "inbox", 2)
+	require.NoError(t, err)
+	assert.Equal(t, 2, msg.Index)
+	assert.Equal(t, 2, page)
+	assert.Equal(t, 3, position)
+
+	_, err = c.DeleteByIndex(ctx, "inbox", 2)
+	require.NoError(t, err)
+
+	for _, msg := range router.Inbox() {
+		assert.NotEqual(t, 2, msg.Index)
+	}
+	assert.Len(t, router.Inbox(), 11)
+
+	_, err = c.DeleteByIndex(ctx, "inbox", 2)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestDo(t *testing.T) {
+	c, router := newTestClient(t)
+	ctx := context.Background()
+	router.SetStatus("LTE_NET_STATUS", map[string]string{"sigLevel": "3", "rfInfoRsrp": "-101"})
+
+	resp, err := c.Do(ctx, []Request{{
+		Method:     ActGet,
+		Controller: "LTE_NET_STATUS",
+		Stack:      "2,1,0,0,0,0",
+		Attrs:      []string{"sigLevel"},
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, 0, resp.Error)
+	assert.Equal(t, []map[string]interface{}{{"sigLevel": "3"}}, resp.Data)
+
+	resp, err = c.Do(ctx, []Request{{
+		Method:     ActSet,
+		Controller: "LTE_NET_STATUS",
+		Attrs:      map[string]interface{}{"sigLevel": 5},
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, 0, resp.Error)
+	assert.Equal(t, "5", router.Status("LTE_NET_STATUS")["sigLevel"])
+
+	resp, err = c.Do(ctx, []Request{{Method: ActGet, Controller: "NO_SUCH_CONTROLLER"}})
+	require.NoError(t, err)
+	assert.NotZero(t, resp.Error)
+}
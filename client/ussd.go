@@ -0,0 +1,124 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/titpetric/tp-link-cli/model"
+)
+
+// ussdController sends USSD requests and reports the session state.
+const ussdController = "LTE_USSD"
+
+// Actions of a USSD set.
+const (
+	ussdActionSend   = 1
+	ussdActionCancel = 2
+)
+
+// ussdStatus values of LTE_USSD.
+const (
+	ussdWaiting  = "0"
+	ussdReceived = "1"
+	ussdFailed   = "2"
+)
+
+// ussdSessionOpen is the sessionStatus of a session waiting for a reply.
+const ussdSessionOpen = "1"
+
+// USSDTimeout limits the wait for a USSD response.
+const USSDTimeout = 60 * time.Second
+
+// ussdPollInterval is the interval between USSD status polls.
+var ussdPollInterval = time.Second
+
+// SendUSSD sends a USSD code, or a menu choice in an open session, and
+// polls until the network responds. If the response is Open, the
+// session waits for a reply: send the choice with SendUSSD, or end the
+// session with CancelUSSD.
+func (c *SMSClient) SendUSSD(ctx context.Context, code string) (*model.USSDResponse, error) {
+	if code == "" {
+		return nil, fmt.Errorf("USSD code is empty")
+	}
+
+	reqs := []Request{
+		{
+			Method:     ActSet,
+			Controller: ussdController,
+			Attrs: map[string]interface{}{
+				"action":     ussdActionSend,
+				"reqContent": code,
+			},
+		},
+	}
+
+	resp, err := c.execute(ctx, reqs)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Error != 0 {
+		return nil, fmt.Errorf("router returned error code: %d", resp.Error)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, USSDTimeout)
+	defer cancel()
+
+	ticker := time.NewTicker(ussdPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("no USSD response: %w", ctx.Err())
+		case <-ticker.C:
+		}
+
+		attrs, err := c.status(ctx, []Request{
+			{
+				Method:     ActGet,
+				Controller: ussdController,
+				Attrs:      []string{"ussdStatus", "sessionStatus", "response"},
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		switch attrString(attrs, "ussdStatus") {
+		case ussdWaiting:
+			continue
+		case ussdReceived:
+			return &model.USSDResponse{
+				Request: code,
+				Text:    attrString(attrs, "response"),
+				Open:    attrString(attrs, "sessionStatus") == ussdSessionOpen,
+			}, nil
+		case ussdFailed:
+			return nil, fmt.Errorf("USSD request %s failed", code)
+		}
+		return nil, fmt.Errorf("unknown USSD status: %q", attrString(attrs, "ussdStatus"))
+	}
+}
+
+// CancelUSSD ends an open USSD session.
+func (c *SMSClient) CancelUSSD(ctx context.Context) error {
+	reqs := []Request{
+		{
+			Method:     ActSet,
+			Controller: ussdController,
+			Attrs: map[string]interface{}{
+				"action": ussdActionCancel,
+			},
+		},
+	}
+
+	resp, err := c.execute(ctx, reqs)
+	if err != nil {
+		return err
+	}
+	if resp.Error != 0 {
+		return fmt.Errorf("router returned error code: %d", resp.Error)
+	}
+	return nil
+}
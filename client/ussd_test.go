@@ -0,0 +1,68 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// balanceMenu answers *123# with a menu, and 1 with the balance.
+func balanceMenu(session []string) (string, bool, bool) {
+	switch {
+	case session[0] != "*123#":
+		return "", false, false
+	case len(session) == 1:
+		return "1. Balance\n2. Bundles", true, true
+	case session[1] == "1":
+		return "Balance: 4.20 EUR", false, true
+	}
+	return "Invalid choice", false, true
+}
+
+func TestSendUSSD(t *testing.T) {
+	defer func(d time.Duration) { ussdPollInterval = d }(ussdPollInterval)
+	ussdPollInterval = 10 * time.Millisecond
+
+	c, router := newTestClient(t)
+	router.HandleUSSD(balanceMenu)
+	ctx := context.Background()
+
+	resp, err := c.SendUSSD(ctx, "*123#")
+	require.NoError(t, err)
+	assert.Equal(t, "*123#", resp.Request)
+	assert.Equal(t, "1. Balance\n2. Bundles", resp.Text)
+	assert.True(t, resp.Open)
+
+	resp, err = c.SendUSSD(ctx, "1")
+	require.NoError(t, err)
+	assert.Equal(t, "Balance: 4.20 EUR", resp.Text)
+	assert.False(t, resp.Open)
+	assert.Equal(t, []string{"*123#", "1"}, router.USSDSession())
+
+	// A closed session starts over
+	_, err = c.SendUSSD(ctx, "*100#")
+	require.Error(t, err)
+	assert.Equal(t, []string{"*100#"}, router.USSDSession())
+}
+
+func TestCancelUSSD(t *testing.T) {
+	defer func(d time.Duration) { ussdPollInterval = d }(ussdPollInterval)
+	ussdPollInterval = 10 * time.Millisecond
+
+	c, router := newTestClient(t)
+	router.HandleUSSD(balanceMenu)
+	ctx := context.Background()
+
+	resp, err := c.SendUSSD(ctx, "*123#")
+	require.NoError(t, err)
+	require.True(t, resp.Open)
+
+	require.NoError(t, c.CancelUSSD(ctx))
+	assert.Empty(t, router.USSDSession())
+
+	_, err = c.SendUSSD(ctx, "")
+	assert.Error(t, err)
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/titpetric/tp-link-cli/model"
+)
+
+// sizeUnits are the size suffixes, in powers of 1024 as on the router
+var sizeUnits = []string{"", "K", "M", "G", "T"}
+
+// DataUsage prints the mobile data statistics and limit
+func (c *SMSCommand) DataUsage(ctx context.Context) error {
+	smsClient, err := c.NewClient()
+	if err != nil {
+		return err
+	}
+	defer c.CloseClient(ctx, smsClient)
+
+	usage, err := smsClient.DataUsage(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get data usage: %w", err)
+	}
+
+	return c.outputValue(os.Stdout, usage, dataUsageRows(usage))
+}
+
+// DataReset clears the data statistics
+func (c *SMSCommand) DataReset(ctx context.Context) error {
+	smsClient, err := c.NewClient()
+	if err != nil {
+		return err
+	}
+	defer c.CloseClient(ctx, smsClient)
+
+	if err := smsClient.ResetDataUsage(ctx); err != nil {
+		return fmt.Errorf("failed to reset data usage: %w", err)
+	}
+	fmt.Println("Data usage reset")
+	return nil
+}
+
+// DataSetLimit sets the data limit to a size, or disables it with off.
+// The alert threshold and billing day are kept unless given.
+func (c *SMSCommand) DataSetLimit(ctx context.Context, size string) error {
+	limit := model.DataLimit{}
+	if size != "off" {
+		n, err := parseSize(size)
+		if err != nil {
+			return err
+		}
+		limit.Enabled, limit.Limit = true, n
+	}
+
+	smsClient, err := c.NewClient()
+	if err != nil {
+		return err
+	}
+	defer c.CloseClient(ctx, smsClient)
+
+	usage, err := smsClient.DataUsage(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get data usage: %w", err)
+	}
+	limit.AlertPercent, limit.BillingDay = usage.AlertPercent, usage.BillingDay
+
+	if c.Alert != "" {
+		if limit.AlertPercent, err = strconv.Atoi(strings.TrimSuffix(c.Alert, "%")); err != nil {
+			return fmt.Errorf("invalid alert: %s", c.Alert)
+		}
+	}
+	if c.BillingDay != "" {
+		if limit.BillingDay, err = strconv.Atoi(c.BillingDay); err != nil {
+			return fmt.Errorf("invalid billing day: %s", c.BillingDay)
+		}
+	}
+
+	if err := smsClient.SetDataLimit(ctx, limit); err != nil {
+		return fmt.Errorf("failed to set data limit: %w", err)
+	}
+
+	if !limit.Enabled {
+		fmt.Println("Data limit disabled")
+		return nil
+	}
+	fmt.Printf("Data limit set to %s, alert at %d%%\n", formatSize(limit.Limit), limit.AlertPercent)
+	return nil
+}
+
+// dataUsageRows returns the usage as field, value rows
+func dataUsageRows(u *model.DataUsage) [][]string {
+	rows := [][]string{
+		{"Period", formatSize(u.Period)},
+		{"Total", formatSize(u.Total)},
+		{"Received", formatSize(u.RX)},
+		{"Sent", formatSize(u.TX)},
+		{"Download rate", formatSize(u.RXRate) + "/s"},
+		{"Upload rate", formatSize(u.TXRate) + "/s"},
+		{"Limit", "off"},
+	}
+	if u.Enabled {
+		rows[len(rows)-1][1] = formatSize(u.Limit)
+		rows = append(rows,
+			[]string{"Remaining", formatSize(u.Remaining())},
+			[]string{"Alert", fmt.Sprintf("%d%%", u.AlertPercent)},
+		)
+	}
+	billingDay := "manual reset"
+	if u.BillingDay > 0 {
+		billingDay = fmt.Sprintf("day %d of the month", u.BillingDay)
+	}
+	return append(rows, []string{"Billing period", billingDay})
+}
+
+// parseSize parses a size in bytes, with an optional K, M, G or T suffix
+func parseSize(s string) (uint64, error) {
+	number := strings.TrimSuffix(strings.ToUpper(s), "B")
+	multiplier := 1.0
+	for i := len(sizeUnits) - 1; i > 0; i-- {
+		if n, ok := strings.CutSuffix(number, sizeUnits[i]); ok {
+			number, multiplier = n, math.Pow(1024, float64(i))
+			break
+		}
+	}
+
+	f, err := strconv.ParseFloat(number, 64)
+	if err != nil || f <= 0 {
+		return 0, fmt.Errorf("invalid size: %s", s)
+	}
+	return uint64(f * multiplier), nil
+}
+
+// formatSize formats a size in bytes with the largest whole unit
+func formatSize(n uint64) string {
+	f := float64(n)
+	unit := 0
+	for f >= 1024 && unit < len(sizeUnits)-1 {
+		f /= 1024
+		unit++
+	}
+	return strconv.FormatFloat(math.Round(f*100)/100, 'f', -1, 64) + sizeUnits[unit]
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSize(t *testing.T) {
+	for input, want := range map[string]uint64{
+		"1024": 1024,
+		"200G": 200 << 30,
+		"1.5g": 3 << 29,
+		"500M": 500 << 20,
+		"2TB":  2 << 40,
+	} {
+		got, err := parseSize(input)
+		require.NoError(t, err, input)
+		assert.Equal(t, want, got, input)
+	}
+
+	for _, input := range []string{"", "0", "-1G", "lots"} {
+		_, err := parseSize(input)
+		assert.Error(t, err, input)
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	assert.Equal(t, "0", formatSize(0))
+	assert.Equal(t, "512", formatSize(512))
+	assert.Equal(t, "200G", formatSize(200<<30))
+	assert.Equal(t, "1.5M", formatSize(3<<19))
+}
@@ -0,0 +1,170 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"github.com/titpetric/tp-link-cli/model"
+)
+
+// DefaultTimeout limits the delivery of a message to the relay.
+const DefaultTimeout = 30 * time.Second
+
+// Deleter deletes inbox messages, as implemented by client.SMSClient.
+type Deleter interface {
+	DeleteByIndex(ctx context.Context, folder string, index int) (*model.DeleteResponse, error)
+}
+
+// Forwarder relays SMS messages to an SMTP server as individual emails,
+// from <number>@sms.local and dated with the time the SMS was received.
+type Forwarder struct {
+	// Addr is the host:port of the SMTP relay.
+	Addr string
+	// Auth authenticates with the relay, if set. STARTTLS is used when
+	// the relay offers it.
+	Auth smtp.Auth
+
+	// From is the envelope sender, <number>@sms.local if empty.
+	From string
+	To   []string
+
+	// Deleter deletes messages from the inbox once delivered, if set.
+	Deleter Deleter
+
+	// Logf reports delivered messages and failed deletes, if set.
+	Logf func(format string, args ...interface{})
+}
+
+// Handle delivers the message, and deletes it from the inbox after a
+// successful delivery. A failed delete is only logged, so the message
+// is not delivered again.
+func (f *Forwarder) Handle(ctx context.Context, msg model.SMSMessage) error {
+	if err := f.Deliver(ctx, msg); err != nil {
+		return err
+	}
+	f.logf("forwarded message %d from %s to %s", msg.Index, msg.From, strings.Join(f.To, ", "))
+
+	if f.Deleter == nil {
+		return nil
+	}
+	resp, err := f.Deleter.DeleteByIndex(ctx, "inbox", msg.Index)
+	if err == nil && resp.Error != 0 {
+		err = fmt.Errorf("router returned error code: %d", resp.Error)
+	}
+	if err != nil {
+		f.logf("failed to delete message %d: %v", msg.Index, err)
+	}
+	return nil
+}
+
+// Deliver sends the message as an email to the relay.
+func (f *Forwarder) Deliver(ctx context.Context, msg model.SMSMessage) error {
+	if len(f.To) == 0 {
+		return fmt.Errorf("no recipients")
+	}
+
+	from := f.From
+	if from == "" {
+		from = Address(msg.From)
+	}
+
+	dialer := &net.Dialer{Timeout: DefaultTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", f.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to connect to relay: %w", err)
+	}
+	conn.SetDeadline(time.Now().Add(DefaultTimeout))
+
+	host, _, _ := net.SplitHostPort(f.Addr)
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to connect to relay: %w", err)
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return fmt.Errorf("failed to start TLS: %w", err)
+		}
+	}
+	if f.Auth != nil {
+		if err := c.Auth(f.Auth); err != nil {
+			return fmt.Errorf("failed to authenticate: %w", err)
+		}
+	}
+
+	if err := c.Mail(from); err != nil {
+		return fmt.Errorf("relay rejected sender %s: %w", from, err)
+	}
+	for _, to := range f.To {
+		if err := c.Rcpt(to); err != nil {
+			return fmt.Errorf("relay rejected recipient %s: %w", to, err)
+		}
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return fmt.Errorf("relay rejected data: %w", err)
+	}
+	if _, err := w.Write(Message(msg, f.To)); err != nil {
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("relay rejected message: %w", err)
+	}
+	return c.Quit()
+}
+
+func (f *Forwarder) logf(format string, args ...interface{}) {
+	if f.Logf != nil {
+		f.Logf(format, args...)
+	}
+}
+
+// Message formats an SMS as an email to the recipients. The sender
+// number is encoded in the From header as <number>@sms.local, and the
+// Message-ID is derived from the content hash.
+func Message(msg model.SMSMessage, to []string) []byte {
+	date := msg.Time()
+	if date.IsZero() {
+		date = time.Now()
+	}
+
+	number := msg.From
+	if number == "" {
+		number = "unknown"
+	}
+	from := mail.Address{Name: number, Address: Address(msg.From)}
+
+	var body bytes.Buffer
+	qp := quotedprintable.NewWriter(&body)
+	qp.Write([]byte(strings.ReplaceAll(msg.Content, "\n", "\r\n")))
+	qp.Close()
+
+	var buf bytes.Buffer
+	header := func(name, value string) {
+		fmt.Fprintf(&buf, "%s: %s\r\n", name, value)
+	}
+	header("From", from.String())
+	header("To", strings.Join(to, ", "))
+	header("Subject", mime.QEncoding.Encode("utf-8", "SMS from "+number))
+	header("Date", date.Format(time.RFC1123Z))
+	header("Message-ID", "<"+msg.ContentHash()+"@"+Domain+">")
+	header("MIME-Version", "1.0")
+	header("Content-Type", "text/plain; charset=utf-8")
+	header("Content-Transfer-Encoding", "quoted-printable")
+	buf.WriteString("\r\n")
+	buf.Write(body.Bytes())
+	buf.WriteString("\r\n")
+	return buf.Bytes()
+}
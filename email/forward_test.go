@@ -0,0 +1,197 @@
+package email
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/titpetric/tp-link-cli/client"
+	"github.com/titpetric/tp-link-cli/fakerouter"
+	"github.com/titpetric/tp-link-cli/model"
+)
+
+// relayMail is a message received by the fake relay.
+type relayMail struct {
+	From string
+	To   []string
+	Data string
+}
+
+// fakeRelay is a minimal SMTP server which records delivered mail, and
+// rejects DATA with the reject reply if set.
+type fakeRelay struct {
+	addr   string
+	reject string
+
+	mu   sync.Mutex
+	mail []relayMail
+}
+
+func newFakeRelay(t *testing.T) *fakeRelay {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { l.Close() })
+
+	relay := &fakeRelay{addr: l.Addr().String()}
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go relay.serve(conn)
+		}
+	}()
+	return relay
+}
+
+func (r *fakeRelay) serve(conn net.Conn) {
+	defer conn.Close()
+	tc := textproto.NewConn(conn)
+	tc.PrintfLine("220 relay ready")
+
+	var m relayMail
+	for {
+		line, err := tc.ReadLine()
+		if err != nil {
+			return
+		}
+		verb, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(verb) {
+		case "MAIL":
+			m = relayMail{From: strings.Trim(arg[len("FROM:"):], "<>")}
+			tc.PrintfLine("250 Ok")
+		case "RCPT":
+			m.To = append(m.To, strings.Trim(arg[len("TO:"):], "<>"))
+			tc.PrintfLine("250 Ok")
+		case "DATA":
+			tc.PrintfLine("354 Go ahead")
+			data, _ := io.ReadAll(tc.DotReader())
+			if r.reject != "" {
+				tc.PrintfLine("%s", r.reject)
+				continue
+			}
+			m.Data = string(data)
+			r.mu.Lock()
+			r.mail = append(r.mail, m)
+			r.mu.Unlock()
+			tc.PrintfLine("250 Ok")
+		case "QUIT":
+			tc.PrintfLine("221 Bye")
+			return
+		default:
+			tc.PrintfLine("250 Ok")
+		}
+	}
+}
+
+func (r *fakeRelay) Mail() []relayMail {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]relayMail(nil), r.mail...)
+}
+
+func TestMessage(t *testing.T) {
+	msg := model.SMSMessage{
+		Index:    3,
+		Folder:   "inbox",
+		From:     "+38640111222",
+		Content:  "Stanje računa: 5 EUR\nHvala",
+		RecvTime: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+
+	parsed, err := mail.ReadMessage(strings.NewReader(string(Message(msg, []string{"ops@example.com"}))))
+	require.NoError(t, err)
+
+	from, err := mail.ParseAddress(parsed.Header.Get("From"))
+	require.NoError(t, err)
+	assert.Equal(t, "+38640111222", from.Name)
+	assert.Equal(t, "+38640111222@sms.local", from.Address)
+
+	date, err := parsed.Header.Date()
+	require.NoError(t, err)
+	assert.True(t, msg.RecvTime.Equal(date))
+
+	assert.Equal(t, "ops@example.com", parsed.Header.Get("To"))
+	assert.Equal(t, "<"+msg.ContentHash()+"@sms.local>", parsed.Header.Get("Message-ID"))
+
+	text, err := plainText(parsed.Header.Get("Content-Type"), parsed.Header.Get("Content-Transfer-Encoding"), parsed.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "Stanje računa: 5 EUR\nHvala\n", text)
+}
+
+func TestForwarderDelete(t *testing.T) {
+	relay := newFakeRelay(t)
+
+	router := fakerouter.New("admin", "secret")
+	defer router.Close()
+	router.Receive("13909", "first", time.Now().Add(-time.Hour))
+	router.Receive("13910", "second", time.Now())
+
+	c, err := client.NewSMSClient(&client.Options{Auth: "admin:secret", Host: router.URL()})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	resp, err := c.ListAll(ctx, "inbox")
+	require.NoError(t, err)
+	require.Len(t, resp.Data, 2)
+
+	f := &Forwarder{
+		Addr:    relay.addr,
+		To:      []string{"ops@example.com"},
+		Deleter: c,
+	}
+	for _, msg := range resp.Data {
+		require.NoError(t, f.Handle(ctx, msg))
+	}
+
+	delivered := relay.Mail()
+	require.Len(t, delivered, 2)
+	for _, m := range delivered {
+		assert.Equal(t, []string{"ops@example.com"}, m.To)
+	}
+	senders := []string{delivered[0].From, delivered[1].From}
+	assert.ElementsMatch(t, []string{"13909@sms.local", "13910@sms.local"}, senders)
+	assert.Empty(t, router.Inbox())
+}
+
+func TestForwarderRejected(t *testing.T) {
+	relay := newFakeRelay(t)
+	relay.reject = "554 5.7.1 Rejected"
+
+	router := fakerouter.New("admin", "secret")
+	defer router.Close()
+	router.Receive("13909", "first", time.Now())
+
+	c, err := client.NewSMSClient(&client.Options{Auth: "admin:secret", Host: router.URL()})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	resp, err := c.ListAll(ctx, "inbox")
+	require.NoError(t, err)
+	require.Len(t, resp.Data, 1)
+
+	f := &Forwarder{
+		Addr:    relay.addr,
+		From:    "router@example.com",
+		To:      []string{"ops@example.com"},
+		Deleter: c,
+	}
+	err = f.Handle(ctx, resp.Data[0])
+	assert.ErrorContains(t, err, "554")
+
+	// The message is kept for another attempt
+	assert.Empty(t, relay.Mail())
+	assert.Len(t, router.Inbox(), 1)
+}
@@ -0,0 +1,64 @@
+package email
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestText(t *testing.T) {
+	tests := map[string]struct {
+		message string
+		want    string
+	}{
+		"plain": {
+			message: "Subject: Alert\r\n\r\nLoad is high.\r\nCheck it.\r\n",
+			want:    "Alert\nLoad is high.\nCheck it.",
+		},
+		"subject only": {
+			message: "Subject: Alert\r\n\r\n",
+			want:    "Alert",
+		},
+		"quoted-printable": {
+			message: "Subject: QP\r\nContent-Type: text/plain; charset=utf-8\r\nContent-Transfer-Encoding: quoted-printable\r\n\r\nDisk =C4=8Disto=\r\n full\r\n",
+			want:    "QP\nDisk čisto full",
+		},
+		"base64": {
+			message: "Content-Type: text/plain\r\nContent-Transfer-Encoding: base64\r\n\r\naGVsbG8g\r\nd29ybGQ=\r\n",
+			want:    "hello world",
+		},
+		"html only": {
+			message: "Subject: HTML\r\nContent-Type: text/html\r\n\r\n<p>hi</p>\r\n",
+			want:    "HTML",
+		},
+		"multipart": {
+			message: strings.Join([]string{
+				"Subject: Multi",
+				`Content-Type: multipart/alternative; boundary="b1"`,
+				"",
+				"--b1",
+				"Content-Type: text/html",
+				"",
+				"<p>html</p>",
+				"--b1",
+				"Content-Type: text/plain; charset=utf-8",
+				"Content-Transfer-Encoding: quoted-printable",
+				"",
+				"plain =C4=8D",
+				"--b1--",
+				"",
+			}, "\r\n"),
+			want: "Multi\nplain č",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			text, err := Text(strings.NewReader(tc.message))
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, text)
+		})
+	}
+}
@@ -0,0 +1,357 @@
+// Package email bridges SMS and email, with an SMTP server that sends
+// mail as SMS and a forwarder that relays SMS as mail.
+package email
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/textproto"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/titpetric/tp-link-cli/model"
+)
+
+// Domain is the mail domain of phone numbers, <number>@sms.local.
+const Domain = "sms.local"
+
+// Server defaults.
+const (
+	DefaultMaxSize = 1 << 20
+	commandTimeout = 5 * time.Minute
+)
+
+// numberPattern matches the local part of a recipient address.
+var numberPattern = regexp.MustCompile(`^\+?[0-9]{3,20}$`)
+
+// Sender sends SMS messages, as implemented by client.SMSClient.
+type Sender interface {
+	Send(ctx context.Context, number, message string) (*model.SendResponse, error)
+}
+
+// Server is an SMTP server which sends mail to <number>@sms.local as
+// an SMS to the number. It has no TLS or authentication, and should be
+// reachable from trusted hosts only.
+type Server struct {
+	Sender Sender
+
+	// AllowFrom lists the envelope senders allowed to send mail, as
+	// addresses, or domains as "@example.com". Empty allows any sender.
+	AllowFrom []string
+
+	// RateLimit limits the messages sent to a number within
+	// RateWindow. Zero disables the limit.
+	RateLimit  int
+	RateWindow time.Duration
+
+	// Hostname is used in the greeting, os.Hostname if empty.
+	Hostname string
+	// MaxSize limits the message size, 1MB if zero.
+	MaxSize int64
+
+	// Logf reports delivered and rejected messages, if set.
+	Logf func(format string, args ...interface{})
+
+	sendMu sync.Mutex
+
+	rateMu sync.Mutex
+	sent   map[string][]time.Time
+}
+
+// Serve accepts SMTP connections on l until the context is cancelled.
+func (s *Server) Serve(ctx context.Context, l net.Listener) error {
+	go func() {
+		<-ctx.Done()
+		l.Close()
+	}()
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer conn.Close()
+			s.serveConn(ctx, conn)
+		}()
+	}
+}
+
+// session is the state of one SMTP connection.
+type session struct {
+	mail bool
+	from string
+	to   []string
+}
+
+func (s *Server) serveConn(ctx context.Context, conn net.Conn) {
+	tc := textproto.NewConn(conn)
+
+	hostname := s.Hostname
+	if hostname == "" {
+		hostname, _ = os.Hostname()
+	}
+	maxSize := s.MaxSize
+	if maxSize <= 0 {
+		maxSize = DefaultMaxSize
+	}
+
+	reply := func(code int, format string, args ...interface{}) {
+		tc.PrintfLine("%d %s", code, fmt.Sprintf(format, args...))
+	}
+
+	reply(220, "%s ESMTP tp-link-cli ready", hostname)
+
+	var sess session
+	for {
+		conn.SetDeadline(time.Now().Add(commandTimeout))
+
+		line, err := tc.ReadLine()
+		if err != nil {
+			return
+		}
+		verb, arg, _ := strings.Cut(line, " ")
+
+		switch strings.ToUpper(verb) {
+		case "HELO":
+			sess = session{}
+			reply(250, "%s", hostname)
+		case "EHLO":
+			sess = session{}
+			tc.PrintfLine("250-%s", hostname)
+			tc.PrintfLine("250-8BITMIME")
+			tc.PrintfLine("250-SIZE %d", maxSize)
+			tc.PrintfLine("250 ENHANCEDSTATUSCODES")
+		case "MAIL":
+			from, ok := parsePath(arg, "FROM:")
+			if !ok {
+				reply(501, "5.5.4 Syntax: MAIL FROM:<address>")
+				continue
+			}
+			if !s.allowed(from) {
+				s.logf("rejected sender %q", from)
+				reply(550, "5.7.1 Sender not allowed")
+				continue
+			}
+			sess = session{mail: true, from: from}
+			reply(250, "2.1.0 Ok")
+		case "RCPT":
+			if !sess.mail {
+				reply(503, "5.5.1 Need MAIL first")
+				continue
+			}
+			to, ok := parsePath(arg, "TO:")
+			if !ok {
+				reply(501, "5.5.4 Syntax: RCPT TO:<address>")
+				continue
+			}
+			number, ok := Number(to)
+			if !ok {
+				reply(550, "5.1.1 Recipient must be <number>@%s", Domain)
+				continue
+			}
+			if !s.allowRate(number) {
+				s.logf("rate limit exceeded for %s", number)
+				reply(450, "4.7.1 Rate limit exceeded for %s", number)
+				continue
+			}
+			sess.to = append(sess.to, number)
+			reply(250, "2.1.5 Ok")
+		case "DATA":
+			if len(sess.to) == 0 {
+				reply(503, "5.5.1 Need RCPT first")
+				continue
+			}
+			reply(354, "End data with <CR><LF>.<CR><LF>")
+
+			code, msg := s.deliver(ctx, sess, tc.DotReader(), maxSize)
+			reply(code, "%s", msg)
+			sess = session{}
+		case "RSET":
+			sess = session{}
+			reply(250, "2.0.0 Ok")
+		case "NOOP":
+			reply(250, "2.0.0 Ok")
+		case "QUIT":
+			reply(221, "2.0.0 Bye")
+			return
+		default:
+			reply(502, "5.5.2 Command not implemented")
+		}
+	}
+}
+
+// deliver sends the message to the session recipients, and returns
+// the SMTP reply for the DATA command. The reply is a temporary failure
+// only when no SMS was sent.
+func (s *Server) deliver(ctx context.Context, sess session, r io.Reader, maxSize int64) (int, string) {
+	data, err := io.ReadAll(io.LimitReader(r, maxSize+1))
+	if err != nil {
+		return 451, "4.3.0 Failed to read message"
+	}
+	if int64(len(data)) > maxSize {
+		io.Copy(io.Discard, r)
+		return 552, "5.3.4 Message too big"
+	}
+
+	text, err := Text(bytes.NewReader(data))
+	if err != nil {
+		return 554, "5.6.0 " + err.Error()
+	}
+	if text == "" {
+		return 554, "5.6.0 Message has no subject or text body"
+	}
+
+	s.sendMu.Lock()
+	defer s.sendMu.Unlock()
+
+	var failed []string
+	for _, number := range sess.to {
+		// Checked again, as other sessions may have sent since RCPT
+		if !s.allowRate(number) {
+			s.logf("rate limit exceeded for %s", number)
+			failed = append(failed, number)
+			continue
+		}
+		if err := s.send(ctx, number, text); err != nil {
+			s.logf("failed to send SMS from %s to %s: %v", sess.from, number, err)
+			failed = append(failed, number)
+			continue
+		}
+		s.recordRate(number)
+		s.logf("sent SMS from %s to %s", sess.from, number)
+	}
+
+	// A temporary failure makes the client retry every recipient, so it
+	// is only safe when nothing was sent. Failures for some recipients
+	// are logged above, and the message is accepted.
+	switch {
+	case len(failed) == len(sess.to):
+		return 451, "4.3.0 Failed to send SMS to " + strings.Join(failed, ", ")
+	case len(failed) > 0:
+		return 250, "2.0.0 Ok: sent, failed for " + strings.Join(failed, ", ")
+	}
+	return 250, "2.0.0 Ok: sent"
+}
+
+func (s *Server) send(ctx context.Context, number, text string) error {
+	resp, err := s.Sender.Send(ctx, number, text)
+	if err != nil {
+		return err
+	}
+	if resp.Error != 0 {
+		return fmt.Errorf("router returned error code: %d", resp.Error)
+	}
+	return nil
+}
+
+// allowed reports whether the envelope sender is in AllowFrom.
+func (s *Server) allowed(from string) bool {
+	if len(s.AllowFrom) == 0 {
+		return true
+	}
+
+	from = strings.ToLower(from)
+	for _, allow := range s.AllowFrom {
+		allow = strings.ToLower(strings.TrimSpace(allow))
+		if allow == from || (strings.HasPrefix(allow, "@") && strings.HasSuffix(from, allow)) {
+			return true
+		}
+	}
+	return false
+}
+
+// allowRate reports whether another message may be sent to number.
+func (s *Server) allowRate(number string) bool {
+	if s.RateLimit <= 0 {
+		return true
+	}
+
+	s.rateMu.Lock()
+	defer s.rateMu.Unlock()
+
+	if s.sent == nil {
+		s.sent = map[string][]time.Time{}
+	}
+	return len(s.recent(number)) < s.RateLimit
+}
+
+func (s *Server) recordRate(number string) {
+	if s.RateLimit <= 0 {
+		return
+	}
+
+	s.rateMu.Lock()
+	defer s.rateMu.Unlock()
+
+	if s.sent == nil {
+		s.sent = map[string][]time.Time{}
+	}
+	s.sent[number] = append(s.recent(number), time.Now())
+}
+
+// recent returns the send times of number within the rate window.
+func (s *Server) recent(number string) []time.Time {
+	cutoff := time.Now().Add(-s.RateWindow)
+	times := s.sent[number]
+	for len(times) > 0 && times[0].Before(cutoff) {
+		times = times[1:]
+	}
+	s.sent[number] = times
+	return times
+}
+
+func (s *Server) logf(format string, args ...interface{}) {
+	if s.Logf != nil {
+		s.Logf(format, args...)
+	}
+}
+
+// Number returns the phone number of a <number>@sms.local address.
+func Number(address string) (string, bool) {
+	local, domain, ok := strings.Cut(address, "@")
+	if !ok || !strings.EqualFold(domain, Domain) || !numberPattern.MatchString(local) {
+		return "", false
+	}
+	return local, true
+}
+
+// Address returns the <number>@sms.local address of a phone number.
+func Address(number string) string {
+	if number == "" {
+		number = "unknown"
+	}
+	return number + "@" + Domain
+}
+
+// parsePath parses the "FROM:<address>" argument of MAIL and RCPT,
+// ignoring any ESMTP parameters after the address.
+func parsePath(arg, prefix string) (string, bool) {
+	if len(arg) < len(prefix) || !strings.EqualFold(arg[:len(prefix)], prefix) {
+		return "", false
+	}
+	arg = strings.TrimSpace(arg[len(prefix):])
+	if !strings.HasPrefix(arg, "<") {
+		return "", false
+	}
+	end := strings.Index(arg, ">")
+	if end < 0 {
+		return "", false
+	}
+	return arg[1:end], true
+}
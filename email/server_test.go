@@ -0,0 +1,167 @@
+package email
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/smtp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/titpetric/tp-link-cli/client"
+	"github.com/titpetric/tp-link-cli/fakerouter"
+	"github.com/titpetric/tp-link-cli/model"
+)
+
+// newTestServer starts an SMTP server sending through a fake router.
+func newTestServer(t *testing.T, configure func(*Server)) (string, *fakerouter.Router) {
+	t.Helper()
+
+	router := fakerouter.New("admin", "secret")
+	t.Cleanup(router.Close)
+
+	c, err := client.NewSMSClient(&client.Options{Auth: "admin:secret", Host: router.URL()})
+	require.NoError(t, err)
+
+	server := &Server{
+		Sender:   c,
+		Hostname: "test",
+	}
+	if configure != nil {
+		configure(server)
+	}
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- server.Serve(ctx, l)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		require.NoError(t, <-done)
+	})
+
+	return l.Addr().String(), router
+}
+
+func sendMail(addr, from string, to []string, body string) error {
+	return smtp.SendMail(addr, nil, from, to, []byte(strings.ReplaceAll(body, "\n", "\r\n")))
+}
+
+func TestServerSend(t *testing.T) {
+	addr, router := newTestServer(t, nil)
+
+	err := sendMail(addr, "nagios@example.com", []string{"13909@sms.local", "+38640111222@SMS.LOCAL"}, `From: nagios@example.com
+To: 13909@sms.local
+Subject: =?utf-8?q?Disk_full_on_db=C4=8D?=
+
+/var is at 98%.
+`)
+	require.NoError(t, err)
+
+	sent := router.Sent()
+	require.Len(t, sent, 2)
+
+	numbers := []string{sent[0].To, sent[1].To}
+	assert.ElementsMatch(t, []string{"13909", "+38640111222"}, numbers)
+	assert.Equal(t, "Disk full on dbč\n/var is at 98%.", sent[0].Content)
+}
+
+func TestServerRejects(t *testing.T) {
+	addr, router := newTestServer(t, func(s *Server) {
+		s.AllowFrom = []string{"nagios@example.com", "@monitoring.local"}
+		s.MaxSize = 1024
+	})
+
+	body := "Subject: alert\n\nbody\n"
+
+	err := sendMail(addr, "spam@example.com", []string{"13909@sms.local"}, body)
+	assert.ErrorContains(t, err, "550")
+
+	err = sendMail(addr, "nagios@example.com", []string{"someone@example.com"}, body)
+	assert.ErrorContains(t, err, "550")
+
+	err = sendMail(addr, "nagios@example.com", []string{"abc@sms.local"}, body)
+	assert.ErrorContains(t, err, "550")
+
+	err = sendMail(addr, "nagios@example.com", []string{"13909@sms.local"}, "Subject: big\n\n"+strings.Repeat("x", 2048)+"\n")
+	assert.ErrorContains(t, err, "552")
+
+	err = sendMail(addr, "nagios@example.com", []string{"13909@sms.local"}, "From: nagios@example.com\n\n\n")
+	assert.ErrorContains(t, err, "554")
+
+	assert.Empty(t, router.Sent())
+
+	require.NoError(t, sendMail(addr, "zabbix@monitoring.local", []string{"13909@sms.local"}, body))
+	assert.Len(t, router.Sent(), 1)
+}
+
+func TestServerRateLimit(t *testing.T) {
+	addr, router := newTestServer(t, func(s *Server) {
+		s.RateLimit = 2
+		s.RateWindow = time.Hour
+	})
+
+	body := "Subject: alert\n\nbody\n"
+	require.NoError(t, sendMail(addr, "nagios@example.com", []string{"13909@sms.local"}, body))
+	require.NoError(t, sendMail(addr, "nagios@example.com", []string{"13909@sms.local"}, body))
+
+	err := sendMail(addr, "nagios@example.com", []string{"13909@sms.local"}, body)
+	assert.ErrorContains(t, err, "450")
+
+	// Other numbers have their own limit
+	require.NoError(t, sendMail(addr, "nagios@example.com", []string{"13910@sms.local"}, body))
+	assert.Len(t, router.Sent(), 3)
+}
+
+// failingSender fails to send to one number.
+type failingSender struct {
+	Sender
+	number string
+}
+
+func (f failingSender) Send(ctx context.Context, number, message string) (*model.SendResponse, error) {
+	if number == f.number {
+		return nil, errors.New("router unavailable")
+	}
+	return f.Sender.Send(ctx, number, message)
+}
+
+func TestServerPartialFailure(t *testing.T) {
+	addr, router := newTestServer(t, func(s *Server) {
+		s.Sender = failingSender{Sender: s.Sender, number: "13910"}
+	})
+
+	body := "Subject: alert\n\nbody\n"
+
+	// Accepted when some SMS were sent, so the client does not send them again
+	require.NoError(t, sendMail(addr, "nagios@example.com", []string{"13909@sms.local", "13910@sms.local"}, body))
+	sent := router.Sent()
+	require.Len(t, sent, 1)
+	assert.Equal(t, "13909", sent[0].To)
+
+	// A temporary failure when nothing was sent
+	err := sendMail(addr, "nagios@example.com", []string{"13910@sms.local"}, body)
+	assert.ErrorContains(t, err, "451")
+	assert.Len(t, router.Sent(), 1)
+}
+
+func TestNumber(t *testing.T) {
+	number, ok := Number("+38640111222@sms.local")
+	assert.True(t, ok)
+	assert.Equal(t, "+38640111222", number)
+
+	for _, address := range []string{"13909@example.com", "abc@sms.local", "13909", "1+2@sms.local"} {
+		_, ok := Number(address)
+		assert.False(t, ok, address)
+	}
+
+	assert.Equal(t, "13909@sms.local", Address("13909"))
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/titpetric/tp-link-cli/exporter"
+)
+
+// Exporter serves Prometheus metrics on /metrics until interrupted
+func (c *SMSCommand) Exporter(ctx context.Context) error {
+	listen := c.Listen
+	if listen == "" {
+		listen = ":9110"
+	}
+
+	exp, err := exporter.NewExporter(c.ClientOptions())
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+	exp.Logf = func(format string, args ...interface{}) {
+		fmt.Fprintf(os.Stderr, "warning: "+format+"\n", args...)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", exp)
+	server := &http.Server{
+		Addr:              listen,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	if err := serveHTTP(ctx, server); err != nil {
+		return err
+	}
+	c.CloseClient(context.Background(), exp.Client)
+	return nil
+}
@@ -0,0 +1,121 @@
+// Package exporter serves router and SMS metrics in the Prometheus
+// text format.
+package exporter
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/titpetric/tp-link-cli/client"
+	"github.com/titpetric/tp-link-cli/model"
+)
+
+// ScrapeTimeout limits the router requests of a scrape.
+const ScrapeTimeout = 30 * time.Second
+
+// Exporter collects the SMS folder totals and LTE signal from the router
+// on every scrape, along with the request metrics of the client.
+type Exporter struct {
+	Client  *client.SMSClient
+	Metrics *Metrics
+
+	// Logf reports failed scrapes, if set.
+	Logf func(format string, args ...interface{})
+
+	mu sync.Mutex
+}
+
+// NewExporter creates a client from opts, observed by the exporter
+// metrics.
+func NewExporter(opts *client.Options) (*Exporter, error) {
+	metrics := NewMetrics()
+	opts.Observer = metrics
+
+	smsClient, err := client.NewSMSClient(opts)
+	if err != nil {
+		return nil, err
+	}
+	return &Exporter{
+		Client:  smsClient,
+		Metrics: metrics,
+	}, nil
+}
+
+// ServeHTTP scrapes the router and writes the metrics. Scrapes are run
+// one at a time, as the router session is not safe for concurrent use.
+func (e *Exporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), ScrapeTimeout)
+	defer cancel()
+
+	var buf bytes.Buffer
+	e.Scrape(ctx, &buf)
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.Write(buf.Bytes())
+}
+
+// Scrape collects the router metrics and writes them, followed by the
+// request metrics. A failed router request gives tplink_up 0, and the
+// LTE metrics are left out if the router does not report the signal.
+func (e *Exporter) Scrape(ctx context.Context, w io.Writer) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	start := time.Now()
+	p := &printer{w: w}
+	up := 1.0
+
+	var inbox *model.BoxInfo
+	p.header("tplink_sms_messages", "gauge", "Messages in the SMS folder.")
+	for _, folder := range []string{"inbox", "sent"} {
+		box, err := e.Client.Box(ctx, folder)
+		if err != nil {
+			e.logf("failed to get %s: %v", folder, err)
+			up = 0
+			continue
+		}
+		p.sample("tplink_sms_messages", float64(box.Total), "folder", folder)
+		if folder == "inbox" {
+			inbox = box
+		}
+	}
+	if inbox != nil {
+		p.header("tplink_sms_unread_messages", "gauge", "Unread messages in the inbox.")
+		p.sample("tplink_sms_unread_messages", float64(inbox.Unread))
+	}
+
+	if up == 1 {
+		signal, err := e.Client.Signal(ctx)
+		if err != nil {
+			e.logf("failed to get signal: %v", err)
+		} else {
+			p.header("tplink_lte_signal_level", "gauge", "LTE signal strength in bars, 0 to 5.")
+			p.sample("tplink_lte_signal_level", float64(signal.Level))
+			p.header("tplink_lte_rsrp_dbm", "gauge", "LTE reference signal received power.")
+			p.sample("tplink_lte_rsrp_dbm", signal.RSRP)
+			p.header("tplink_lte_rsrq_db", "gauge", "LTE reference signal received quality.")
+			p.sample("tplink_lte_rsrq_db", signal.RSRQ)
+			p.header("tplink_lte_snr_db", "gauge", "LTE signal to noise ratio.")
+			p.sample("tplink_lte_snr_db", signal.SNR)
+			p.header("tplink_lte_rssi_dbm", "gauge", "LTE received signal strength indicator.")
+			p.sample("tplink_lte_rssi_dbm", signal.RSSI)
+		}
+	}
+
+	p.header("tplink_up", "gauge", "Whether the last scrape of the router succeeded.")
+	p.sample("tplink_up", up)
+	p.header("tplink_scrape_duration_seconds", "gauge", "Duration of the router scrape.")
+	p.sample("tplink_scrape_duration_seconds", time.Since(start).Seconds())
+
+	e.Metrics.WriteTo(w)
+}
+
+func (e *Exporter) logf(format string, args ...interface{}) {
+	if e.Logf != nil {
+		e.Logf(format, args...)
+	}
+}
@@ -0,0 +1,119 @@
+package exporter
+
+import (
+	"errors"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/titpetric/tp-link-cli/client"
+	"github.com/titpetric/tp-link-cli/fakerouter"
+)
+
+func scrape(t *testing.T, e *Exporter) string {
+	t.Helper()
+
+	server := httptest.NewServer(e)
+	defer server.Close()
+
+	resp, err := server.Client().Get(server.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Contains(t, resp.Header.Get("Content-Type"), "text/plain")
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+func TestExporter(t *testing.T) {
+	router := fakerouter.New("admin", "secret")
+	defer router.Close()
+	router.Receive("13909", "first", time.Now())
+	router.Receive("13910", "second", time.Now())
+	router.SetStatus("LTE_NET_STATUS", map[string]string{
+		"sigLevel":   "4",
+		"rfInfoRsrp": "-95",
+		"rfInfoRsrq": "-11",
+		"rfInfoSnr":  "12.5",
+	})
+
+	e, err := NewExporter(&client.Options{Auth: "admin:secret", Host: router.URL()})
+	require.NoError(t, err)
+
+	body := scrape(t, e)
+	for _, line := range []string{
+		`tplink_up 1`,
+		`tplink_sms_messages{folder="inbox"} 2`,
+		`tplink_sms_messages{folder="sent"} 0`,
+		`tplink_sms_unread_messages 2`,
+		`tplink_lte_signal_level 4`,
+		`tplink_lte_rsrp_dbm -95`,
+		`tplink_lte_rsrq_db -11`,
+		`tplink_lte_snr_db 12.5`,
+		`tplink_router_request_duration_seconds_count{controller="LTE_SMS_RECVMSGBOX"} 1`,
+		`tplink_router_request_duration_seconds_count{controller="LTE_NET_STATUS"} 1`,
+		`tplink_router_logins_total 1`,
+		`tplink_router_login_failures_total 0`,
+	} {
+		assert.Contains(t, body, line+"\n")
+	}
+
+	// The session is reused by the next scrape
+	body = scrape(t, e)
+	assert.Contains(t, body, `tplink_router_request_duration_seconds_count{controller="LTE_SMS_RECVMSGBOX"} 2`+"\n")
+	assert.Contains(t, body, "tplink_router_logins_total 1\n")
+}
+
+func TestExporterWithoutSignal(t *testing.T) {
+	router := fakerouter.New("admin", "secret")
+	defer router.Close()
+
+	e, err := NewExporter(&client.Options{Auth: "admin:secret", Host: router.URL()})
+	require.NoError(t, err)
+
+	body := scrape(t, e)
+	assert.Contains(t, body, "tplink_up 1\n")
+	assert.NotContains(t, body, "tplink_lte_")
+	assert.Contains(t, body, `tplink_router_errors_total{controller="LTE_NET_STATUS",code="9003"} 1`+"\n")
+}
+
+func TestExporterLoginFailure(t *testing.T) {
+	router := fakerouter.New("admin", "secret")
+	defer router.Close()
+
+	e, err := NewExporter(&client.Options{Auth: "admin:wrong", Host: router.URL()})
+	require.NoError(t, err)
+
+	body := scrape(t, e)
+	assert.Contains(t, body, "tplink_up 0\n")
+	assert.NotContains(t, body, "tplink_sms_unread_messages")
+	assert.Contains(t, body, "tplink_router_login_failures_total 2\n")
+}
+
+func TestMetricsHistogram(t *testing.T) {
+	m := NewMetrics()
+	m.ObserveRequest("LTE_SMS_SENDNEWMSG", 80*time.Millisecond, 0, nil)
+	m.ObserveRequest("LTE_SMS_SENDNEWMSG", 3*time.Second, 0, errors.New("timeout"))
+
+	var sb strings.Builder
+	_, err := m.WriteTo(&sb)
+	require.NoError(t, err)
+
+	body := sb.String()
+	for _, line := range []string{
+		`tplink_router_request_duration_seconds_bucket{controller="LTE_SMS_SENDNEWMSG",le="0.05"} 0`,
+		`tplink_router_request_duration_seconds_bucket{controller="LTE_SMS_SENDNEWMSG",le="0.1"} 1`,
+		`tplink_router_request_duration_seconds_bucket{controller="LTE_SMS_SENDNEWMSG",le="5"} 2`,
+		`tplink_router_request_duration_seconds_bucket{controller="LTE_SMS_SENDNEWMSG",le="+Inf"} 2`,
+		`tplink_router_request_duration_seconds_sum{controller="LTE_SMS_SENDNEWMSG"} 3.08`,
+		`tplink_router_request_failures_total{controller="LTE_SMS_SENDNEWMSG"} 1`,
+	} {
+		assert.Contains(t, body, line+"\n")
+	}
+}
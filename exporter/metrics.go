@@ -0,0 +1,182 @@
+package exporter
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// buckets are the request duration histogram buckets, in seconds.
+var buckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Metrics collects router request and login metrics. It implements
+// client.Observer.
+type Metrics struct {
+	mu            sync.Mutex
+	requests      map[string]*histogram
+	failures      map[string]int
+	errors        map[errorKey]int
+	logins        int
+	loginFailures int
+}
+
+type errorKey struct {
+	controller string
+	code       int
+}
+
+type histogram struct {
+	counts []int
+	count  int
+	sum    float64
+}
+
+// NewMetrics returns empty metrics.
+func NewMetrics() *Metrics {
+	return &Metrics{
+		requests: map[string]*histogram{},
+		failures: map[string]int{},
+		errors:   map[errorKey]int{},
+	}
+}
+
+// ObserveRequest records a request to a controller. Requests which
+// failed are counted as failures, router error codes as errors.
+func (m *Metrics) ObserveRequest(controller string, duration time.Duration, code int, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	h := m.requests[controller]
+	if h == nil {
+		h = &histogram{counts: make([]int, len(buckets))}
+		m.requests[controller] = h
+	}
+	seconds := duration.Seconds()
+	for i, le := range buckets {
+		if seconds <= le {
+			h.counts[i]++
+		}
+	}
+	h.count++
+	h.sum += seconds
+
+	if err != nil {
+		m.failures[controller]++
+		return
+	}
+	if code != 0 {
+		m.errors[errorKey{controller, code}]++
+	}
+}
+
+// ObserveLogin records a login.
+func (m *Metrics) ObserveLogin(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.logins++
+	if err != nil {
+		m.loginFailures++
+	}
+}
+
+// WriteTo writes the metrics in the Prometheus text format.
+func (m *Metrics) WriteTo(w io.Writer) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	p := &printer{w: w}
+
+	p.header("tplink_router_request_duration_seconds", "histogram", "Duration of router requests by controller.")
+	for _, controller := range sortedKeys(m.requests) {
+		h := m.requests[controller]
+		for i, le := range buckets {
+			p.sample("tplink_router_request_duration_seconds_bucket", float64(h.counts[i]), "controller", controller, "le", formatFloat(le))
+		}
+		p.sample("tplink_router_request_duration_seconds_bucket", float64(h.count), "controller", controller, "le", "+Inf")
+		p.sample("tplink_router_request_duration_seconds_sum", h.sum, "controller", controller)
+		p.sample("tplink_router_request_duration_seconds_count", float64(h.count), "controller", controller)
+	}
+
+	p.header("tplink_router_request_failures_total", "counter", "Router requests which failed, by controller.")
+	for _, controller := range sortedKeys(m.failures) {
+		p.sample("tplink_router_request_failures_total", float64(m.failures[controller]), "controller", controller)
+	}
+
+	p.header("tplink_router_errors_total", "counter", "Router error codes returned, by controller and code.")
+	keys := make([]errorKey, 0, len(m.errors))
+	for key := range m.errors {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].controller == keys[j].controller {
+			return keys[i].code < keys[j].code
+		}
+		return keys[i].controller < keys[j].controller
+	})
+	for _, key := range keys {
+		p.sample("tplink_router_errors_total", float64(m.errors[key]), "controller", key.controller, "code", strconv.Itoa(key.code))
+	}
+
+	p.header("tplink_router_logins_total", "counter", "Router logins.")
+	p.sample("tplink_router_logins_total", float64(m.logins))
+	p.header("tplink_router_login_failures_total", "counter", "Router logins which failed.")
+	p.sample("tplink_router_login_failures_total", float64(m.loginFailures))
+
+	return p.n, p.err
+}
+
+// printer writes the Prometheus text format, keeping the first error.
+type printer struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+func (p *printer) printf(format string, args ...interface{}) {
+	if p.err != nil {
+		return
+	}
+	n, err := fmt.Fprintf(p.w, format, args...)
+	p.n += int64(n)
+	p.err = err
+}
+
+func (p *printer) header(name, kind, help string) {
+	p.printf("# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+// sample writes a sample, with labels given as name, value pairs.
+func (p *printer) sample(name string, value float64, labels ...string) {
+	if len(labels) == 0 {
+		p.printf("%s %s\n", name, formatFloat(value))
+		return
+	}
+
+	pairs := make([]string, 0, len(labels)/2)
+	for i := 0; i+1 < len(labels); i += 2 {
+		pairs = append(pairs, labels[i]+`="`+escapeLabel(labels[i+1])+`"`)
+	}
+	p.printf("%s{%s} %s\n", name, strings.Join(pairs, ","), formatFloat(value))
+}
+
+func escapeLabel(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,111 @@
+package fakerouter
+
+import "strconv"
+
+// apnController holds the APN profiles. Profiles are addressed by their
+// position, profileID is assigned on add.
+const apnController = "LTE_PROF_CFG"
+
+// SetAPNProfiles replaces the APN profiles. A profile without a
+// profileID gets the next free one.
+func (r *Router) SetAPNProfiles(profiles []map[string]string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.profiles = nil
+	for _, attrs := range profiles {
+		r.addProfile(attrs)
+	}
+}
+
+// APNProfiles returns a copy of the APN profiles.
+func (r *Router) APNProfiles() []map[string]string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result := make([]map[string]string, 0, len(r.profiles))
+	for _, profile := range r.profiles {
+		attrs := map[string]string{}
+		for k, v := range profile {
+			attrs[k] = v
+		}
+		result = append(result, attrs)
+	}
+	return result
+}
+
+func (r *Router) addProfile(attrs map[string]string) map[string]string {
+	profile := map[string]string{"profileType": "1"}
+	for k, v := range attrs {
+		profile[k] = v
+	}
+	if profile["profileID"] == "" {
+		next := 1
+		for _, p := range r.profiles {
+			if id, _ := strconv.Atoi(p["profileID"]); id >= next {
+				next = id + 1
+			}
+		}
+		profile["profileID"] = strconv.Itoa(next)
+	}
+	r.profiles = append(r.profiles, profile)
+	return profile
+}
+
+// handleAPN serves the APN profiles. Built-in profiles, with a
+// profileType of 0, can't be changed or deleted.
+func (r *Router) handleAPN(req request) ([]object, int) {
+	if req.method == actGL {
+		var result []object
+		for i, profile := range r.profiles {
+			result = append(result, profileObject(i+1, profile))
+		}
+		return result, errNone
+	}
+	if req.method == actAdd {
+		values := req.values()
+		delete(values, "profileID")
+		delete(values, "profileType")
+		return []object{profileObject(len(r.profiles)+1, r.addProfile(values))}, errNone
+	}
+
+	pos := req.position()
+	if pos < 1 || pos > len(r.profiles) {
+		return nil, errInvalidStack
+	}
+	profile := r.profiles[pos-1]
+
+	switch req.method {
+	case actGet:
+		return []object{profileObject(pos, profile)}, errNone
+	case actSet:
+		if profile["profileType"] == "0" {
+			return nil, errInvalidArgument
+		}
+		for k, v := range req.values() {
+			if k != "profileID" && k != "profileType" {
+				profile[k] = v
+			}
+		}
+		return nil, errNone
+	case actDel:
+		if profile["profileType"] == "0" {
+			return nil, errInvalidArgument
+		}
+		r.profiles = append(r.profiles[:pos-1], r.profiles[pos:]...)
+		return nil, errNone
+	}
+	return nil, errInvalidArgument
+}
+
+// profileObject returns a profile without its password, which is
+// write only, as a router may not return it.
+func profileObject(pos int, profile map[string]string) object {
+	attrs := map[string]string{}
+	for k, v := range profile {
+		if k != "password" {
+			attrs[k] = v
+		}
+	}
+	return object{stack: strconv.Itoa(pos) + ",0,0,0,0,0", attrs: attrs}
+}
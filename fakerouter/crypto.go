@@ -0,0 +1,161 @@
+package fakerouter
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// rsaKey is a textbook RSA-512 keypair, matching the unpadded scheme
+// the router web UI uses to sign requests.
+type rsaKey struct {
+	n *big.Int
+	e int64
+	d *big.Int
+}
+
+// newRSAKey generates a 512-bit keypair. The standard library refuses
+// to generate keys this small, so the primes are picked by hand.
+func newRSAKey() (*rsaKey, error) {
+	e := big.NewInt(65537)
+	one := big.NewInt(1)
+
+	for {
+		p, err := rand.Prime(rand.Reader, 256)
+		if err != nil {
+			return nil, err
+		}
+		q, err := rand.Prime(rand.Reader, 256)
+		if err != nil {
+			return nil, err
+		}
+
+		n := new(big.Int).Mul(p, q)
+		if n.BitLen() != 512 {
+			continue
+		}
+
+		phi := new(big.Int).Mul(new(big.Int).Sub(p, one), new(big.Int).Sub(q, one))
+		d := new(big.Int).ModInverse(e, phi)
+		if d == nil {
+			continue
+		}
+
+		return &rsaKey{n: n, e: e.Int64(), d: d}, nil
+	}
+}
+
+// modulus returns the modulus as served by /cgi/getParm.
+func (k *rsaKey) modulus() string {
+	return strings.ToUpper(k.n.Text(16))
+}
+
+// exponent returns the public exponent as served by /cgi/getParm.
+func (k *rsaKey) exponent() string {
+	return fmt.Sprintf("%06X", k.e)
+}
+
+// decrypt reverses the client signature, which is a sequence of
+// fixed-width hex blocks, each holding a zero-padded plaintext chunk.
+func (k *rsaKey) decrypt(sign string) (string, error) {
+	blockSize := (k.n.BitLen() + 7) >> 3
+	hexSize := (k.n.BitLen() + 3) / 4
+
+	if len(sign) == 0 || len(sign)%hexSize != 0 {
+		return "", fmt.Errorf("invalid signature length %d", len(sign))
+	}
+
+	var result []byte
+	for i := 0; i < len(sign); i += hexSize {
+		c, ok := new(big.Int).SetString(sign[i:i+hexSize], 16)
+		if !ok {
+			return "", fmt.Errorf("invalid signature block")
+		}
+		m := new(big.Int).Exp(c, k.d, k.n)
+		block := m.FillBytes(make([]byte, blockSize))
+		result = append(result, bytes.TrimRight(block, "\x00")...)
+	}
+
+	return string(result), nil
+}
+
+// aesKey holds the AES-128-CBC parameters a client sent at login.
+type aesKey struct {
+	key []byte
+	iv  []byte
+}
+
+// newAESKey builds the key from the numeric key and iv strings.
+func newAESKey(key, iv string) (*aesKey, error) {
+	if len(key) != 16 || len(iv) != 16 {
+		return nil, fmt.Errorf("invalid aes key length")
+	}
+	return &aesKey{key: []byte(key), iv: []byte(iv)}, nil
+}
+
+func (k *aesKey) encrypt(plaintext string) string {
+	block, _ := aes.NewCipher(k.key)
+
+	data := []byte(plaintext)
+	padLen := aes.BlockSize - len(data)%aes.BlockSize
+	data = append(data, bytes.Repeat([]byte{byte(padLen)}, padLen)...)
+
+	out := make([]byte, len(data))
+	cipher.NewCBCEncrypter(block, k.iv).CryptBlocks(out, data)
+	return base64.StdEncoding.EncodeToString(out)
+}
+
+func (k *aesKey) decrypt(ciphertext string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil {
+		return "", err
+	}
+	if len(data) == 0 || len(data)%aes.BlockSize != 0 {
+		return "", fmt.Errorf("invalid ciphertext length %d", len(data))
+	}
+
+	block, err := aes.NewCipher(k.key)
+	if err != nil {
+		return "", err
+	}
+
+	out := make([]byte, len(data))
+	cipher.NewCBCDecrypter(block, k.iv).CryptBlocks(out, data)
+
+	padLen := int(out[len(out)-1])
+	if padLen == 0 || padLen > aes.BlockSize {
+		return "", fmt.Errorf("invalid padding")
+	}
+	return string(out[:len(out)-padLen]), nil
+}
+
+// parseSign splits a decrypted signature ("key=..&iv=..&h=..&s=..").
+func parseSign(sign string) map[string]string {
+	result := map[string]string{}
+	for _, part := range strings.Split(sign, "&") {
+		if k, v, ok := strings.Cut(part, "="); ok {
+			result[k] = v
+		}
+	}
+	return result
+}
+
+// credentialHash returns the hash the client embeds in each signature.
+func credentialHash(username, password string) string {
+	h := md5.Sum([]byte(username + password))
+	return hex.EncodeToString(h[:])
+}
+
+// randomHex returns n random bytes, hex encoded.
+func randomHex(n int) string {
+	buf := make([]byte, n)
+	rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,153 @@
+package fakerouter
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Action method constants, as used in request data frames.
+const (
+	actGet = 1
+	actSet = 2
+	actAdd = 3
+	actDel = 4
+	actGL  = 5
+	actGS  = 6
+	actCGI = 8
+)
+
+// Error codes returned in the [error] line of a response frame.
+const (
+	errNone              = 0
+	errUnknownController = 9003
+	errInvalidStack      = 9004
+	errInvalidArgument   = 9005
+)
+
+// request is a single section of a request data frame.
+type request struct {
+	method     int
+	controller string
+	stack      string
+	attrs      []string
+}
+
+// names returns the attribute names requested by a get.
+func (r request) names() []string {
+	var result []string
+	for _, attr := range r.attrs {
+		name, _, _ := strings.Cut(attr, "=")
+		result = append(result, name)
+	}
+	return result
+}
+
+// values returns the attributes sent with a set.
+func (r request) values() map[string]string {
+	result := map[string]string{}
+	for _, attr := range r.attrs {
+		if k, v, ok := strings.Cut(attr, "="); ok {
+			result[k] = strings.ReplaceAll(v, "\u0012", "\n")
+		}
+	}
+	return result
+}
+
+// position returns the first stack element, the 1-based instance number.
+func (r request) position() int {
+	first, _, _ := strings.Cut(r.stack, ",")
+	n, _ := strconv.Atoi(first)
+	return n
+}
+
+// object is a single object in a response data frame.
+type object struct {
+	stack   string
+	section int
+	attrs   map[string]string
+}
+
+// filter keeps only the attributes named by the request, if any.
+func (o object) filter(names []string) object {
+	if len(names) == 0 {
+		return o
+	}
+	attrs := map[string]string{}
+	for _, name := range names {
+		if v, ok := o.attrs[name]; ok {
+			attrs[name] = v
+		}
+	}
+	return object{stack: o.stack, section: o.section, attrs: attrs}
+}
+
+var sectionRegex = regexp.MustCompile(`^\[([^#\]]+)#([^#\]]+)#([^#\]]+)\](\d+),(\d+)$`)
+
+// parseFrame decodes a request data frame as produced by the client.
+func parseFrame(frame string) ([]request, error) {
+	lines := strings.Split(frame, "\r\n")
+	if len(lines) < 2 {
+		return nil, fmt.Errorf("frame too short")
+	}
+
+	var methods []int
+	for _, m := range strings.Split(lines[0], "&") {
+		method, err := strconv.Atoi(m)
+		if err != nil {
+			return nil, fmt.Errorf("invalid method %q", m)
+		}
+		methods = append(methods, method)
+	}
+
+	var result []request
+	for i := 1; i < len(lines); i++ {
+		if lines[i] == "" {
+			continue
+		}
+
+		match := sectionRegex.FindStringSubmatch(lines[i])
+		if match == nil {
+			return nil, fmt.Errorf("invalid section header %q", lines[i])
+		}
+
+		idx, _ := strconv.Atoi(match[4])
+		count, _ := strconv.Atoi(match[5])
+		if idx >= len(methods) || i+count >= len(lines) {
+			return nil, fmt.Errorf("invalid section %q", lines[i])
+		}
+
+		result = append(result, request{
+			method:     methods[idx],
+			controller: match[1],
+			stack:      match[2],
+			attrs:      lines[i+1 : i+1+count],
+		})
+		i += count
+	}
+
+	return result, nil
+}
+
+// makeFrame encodes response objects and the error code.
+func makeFrame(objects []object, code int) string {
+	var sb strings.Builder
+	for _, obj := range objects {
+		fmt.Fprintf(&sb, "[%s]%d\n", obj.stack, obj.section)
+
+		keys := make([]string, 0, len(obj.attrs))
+		for k := range obj.attrs {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			v := strings.ReplaceAll(obj.attrs[k], "\n", "\u0012")
+			fmt.Fprintf(&sb, "%s=%s\n", k, v)
+		}
+	}
+	fmt.Fprintf(&sb, "[error]%d\n", code)
+	return sb.String()
+}
@@ -0,0 +1,54 @@
+package fakerouter
+
+import "fmt"
+
+// lteLinkController is the LTE connection controller. A set of enable
+// starts connecting or disconnecting, which completes on the next get.
+const lteLinkController = "WAN_LTE_LINK_CFG"
+
+// LTE connectStatus values.
+const (
+	lteDisabled      = "0"
+	lteConnecting    = "2"
+	lteDisconnecting = "3"
+	lteConnected     = "4"
+)
+
+// LTEConnects returns the number of LTE connections made by enabling
+// the connection.
+func (r *Router) LTEConnects() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.lteConnects
+}
+
+// handleLTELink serves WAN_LTE_LINK_CFG as a status controller, moving
+// the connection between states. Each connection gets a new ipv4.
+func (r *Router) handleLTELink(req request) ([]object, int) {
+	status := r.status[lteLinkController]
+
+	switch req.method {
+	case actGet:
+		switch status["connectStatus"] {
+		case lteConnecting:
+			r.lteConnects++
+			status["connectStatus"] = lteConnected
+			status["ipv4"] = fmt.Sprintf("100.64.0.%d", r.lteConnects)
+		case lteDisconnecting:
+			status["connectStatus"] = lteDisabled
+			status["ipv4"] = "0.0.0.0"
+		}
+	case actSet:
+		switch req.values()["enable"] {
+		case "1":
+			if status["connectStatus"] != lteConnected {
+				status["connectStatus"] = lteConnecting
+			}
+		case "0":
+			if status["connectStatus"] != lteDisabled {
+				status["connectStatus"] = lteDisconnecting
+			}
+		}
+	}
+	return r.handleStatus(req)
+}
@@ -0,0 +1,404 @@
+// Package fakerouter provides an in-process emulator of the TP-Link MR600
+// web interface, so the client can be tested without a router.
+package fakerouter
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Login error code returned by /cgi/login on bad credentials or signature.
+const errLogin = 71234
+
+// Message is an SMS message stored by the fake router.
+type Message struct {
+	Index   int
+	From    string
+	To      string
+	Content string
+	Time    time.Time
+	Unread  bool
+}
+
+// session is the single admin web session the router allows.
+type session struct {
+	id    string
+	token string
+	aes   *aesKey
+}
+
+// Router is a fake TP-Link router serving the web interface endpoints
+// used by the client over an httptest server.
+type Router struct {
+	Username string
+	Password string
+
+	// RebootDelay is how long the router is down after a reboot.
+	RebootDelay time.Duration
+
+	server *httptest.Server
+	key    *rsaKey
+	seq    int
+
+	mu        sync.Mutex
+	session   *session
+	logins    int
+	inbox     []*Message
+	sent      []*Message
+	nextIndex int
+	pages     map[string]int
+	status    map[string]map[string]string
+	ussd      *ussd
+	reboots   int
+	downUntil time.Time
+
+	lteConnects int
+	corrupt     bool
+	profiles    []map[string]string
+	sim         *SIM
+}
+
+// New starts a fake router accepting the given credentials.
+func New(username, password string) *Router {
+	key, err := newRSAKey()
+	if err != nil {
+		panic(fmt.Sprintf("fakerouter: failed to generate rsa key: %v", err))
+	}
+
+	r := &Router{
+		Username:  username,
+		Password:  password,
+		key:       key,
+		seq:       100000000,
+		nextIndex: 1,
+		pages:     map[string]int{},
+		status:    map[string]map[string]string{},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", r.handleHome)
+	mux.HandleFunc("GET /img/loading.gif", r.handleLoading)
+	mux.HandleFunc("POST /cgi/getParm", r.handleGetParm)
+	mux.HandleFunc("POST /cgi/getBusy", r.handleGetBusy)
+	mux.HandleFunc("POST /cgi/login", r.handleLogin)
+	mux.HandleFunc("POST /cgi_gdpr", r.handleGDPR)
+
+	r.server = httptest.NewServer(r.available(mux))
+	return r
+}
+
+// URL returns the base URL of the fake router.
+func (r *Router) URL() string {
+	return r.server.URL
+}
+
+// Close shuts down the fake router.
+func (r *Router) Close() {
+	r.server.Close()
+}
+
+// Receive adds a message to the inbox and returns its index.
+func (r *Router) Receive(from, content string, t time.Time) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	msg := &Message{
+		Index:   r.nextIndex,
+		From:    from,
+		Content: content,
+		Time:    t.UTC().Truncate(time.Second),
+		Unread:  true,
+	}
+	r.nextIndex++
+	r.inbox = append(r.inbox, msg)
+	return msg.Index
+}
+
+// Inbox returns a copy of the inbox, newest first.
+func (r *Router) Inbox() []Message {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return copyMessages(sortMessages(r.inbox))
+}
+
+// Sent returns a copy of the sent folder, newest first.
+func (r *Router) Sent() []Message {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return copyMessages(sortMessages(r.sent))
+}
+
+// LoggedIn returns true if an admin session is active.
+func (r *Router) LoggedIn() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.session != nil
+}
+
+// Logins returns the number of successful logins.
+func (r *Router) Logins() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.logins
+}
+
+// Reboots returns the number of reboots.
+func (r *Router) Reboots() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.reboots
+}
+
+// CorruptNextResponse garbles the response to the next request, after
+// the request has run, as a flaky connection would.
+func (r *Router) CorruptNextResponse() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.corrupt = true
+}
+
+// ExpireSession drops the active session, as the router does on timeout.
+func (r *Router) ExpireSession() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.session = nil
+}
+
+func (r *Router) handleHome(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "text/html")
+
+	if sess := r.lookupSession(req); sess != nil {
+		fmt.Fprintf(w, "<html><script type=\"text/javascript\">var token=\"%s\";</script></html>\n", sess.token)
+		return
+	}
+	fmt.Fprintln(w, "<html><body>login</body></html>")
+}
+
+func (r *Router) handleLoading(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "image/gif")
+	w.Write([]byte("GIF89a"))
+}
+
+func (r *Router) handleGetParm(w http.ResponseWriter, req *http.Request) {
+	fmt.Fprintf(w, "var ee=\"%s\";\nvar nn=\"%s\";\nvar seq=\"%d\";\n$.ret=0;\n",
+		r.key.exponent(), r.key.modulus(), r.seq)
+}
+
+func (r *Router) handleGetBusy(w http.ResponseWriter, req *http.Request) {
+	fmt.Fprintln(w, "var isLogined=0;\nvar isBusy=0;\n$.ret=0;")
+}
+
+func (r *Router) handleLogin(w http.ResponseWriter, req *http.Request) {
+	query := req.URL.Query()
+	data := query.Get("data")
+
+	sign, err := r.key.decrypt(query.Get("sign"))
+	if err != nil {
+		fmt.Fprintf(w, "$.ret=%d;\n", errLogin)
+		return
+	}
+
+	params := parseSign(sign)
+	if !r.validSign(params, data) {
+		fmt.Fprintf(w, "$.ret=%d;\n", errLogin)
+		return
+	}
+
+	key, err := newAESKey(params["key"], params["iv"])
+	if err != nil {
+		fmt.Fprintf(w, "$.ret=%d;\n", errLogin)
+		return
+	}
+
+	credentials, err := key.decrypt(data)
+	if err != nil || credentials != r.Username+"\n"+r.Password {
+		fmt.Fprintf(w, "$.ret=%d;\n", errLogin)
+		return
+	}
+
+	sess := &session{
+		id:    randomHex(16),
+		token: randomHex(16),
+		aes:   key,
+	}
+
+	r.mu.Lock()
+	r.session = sess
+	r.logins++
+	r.mu.Unlock()
+
+	http.SetCookie(w, &http.Cookie{Name: "JSESSIONID", Value: sess.id, Path: "/"})
+	fmt.Fprintln(w, "$.ret=0;")
+}
+
+func (r *Router) handleGDPR(w http.ResponseWriter, req *http.Request) {
+	sess := r.lookupSession(req)
+	if sess == nil || req.Header.Get("TokenID") != sess.token {
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return
+	}
+
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var sign, data string
+	for _, line := range strings.Split(string(body), "\r\n") {
+		if v, ok := strings.CutPrefix(line, "sign="); ok {
+			sign = v
+		} else if v, ok := strings.CutPrefix(line, "data="); ok {
+			data = v
+		}
+	}
+
+	decrypted, err := r.key.decrypt(sign)
+	if err != nil || !r.validSign(parseSign(decrypted), data) {
+		http.Error(w, "bad signature", http.StatusBadRequest)
+		return
+	}
+
+	frame, err := sess.aes.decrypt(data)
+	if err != nil {
+		http.Error(w, "bad data", http.StatusBadRequest)
+		return
+	}
+
+	reqs, err := parseFrame(frame)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	objects, code := r.dispatch(reqs)
+
+	r.mu.Lock()
+	corrupt := r.corrupt
+	r.corrupt = false
+	r.mu.Unlock()
+
+	w.Header().Set("Content-Type", "text/plain")
+	if corrupt {
+		fmt.Fprint(w, "garbled")
+		return
+	}
+	fmt.Fprint(w, sess.aes.encrypt(makeFrame(objects, code)))
+}
+
+// handleLogout ends the active session.
+func (r *Router) handleLogout(req request) ([]object, int) {
+	if req.method != actCGI {
+		return nil, errInvalidArgument
+	}
+	r.session = nil
+	return nil, errNone
+}
+
+// handleReboot ends the active session and takes the router down for
+// RebootDelay, once the response is written.
+func (r *Router) handleReboot(req request) ([]object, int) {
+	if req.method != actCGI {
+		return nil, errInvalidArgument
+	}
+	r.session = nil
+	r.reboots++
+	r.downUntil = time.Now().Add(r.RebootDelay)
+	return nil, errNone
+}
+
+// available answers requests with 503 while the router is rebooting.
+func (r *Router) available(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		r.mu.Lock()
+		down := time.Now().Before(r.downUntil)
+		r.mu.Unlock()
+
+		if down {
+			http.Error(w, "rebooting", http.StatusServiceUnavailable)
+			return
+		}
+		next.ServeHTTP(w, req)
+	})
+}
+
+// validSign checks the credential hash and sequence in a signature.
+func (r *Router) validSign(params map[string]string, data string) bool {
+	if params["h"] != credentialHash(r.Username, r.Password) {
+		return false
+	}
+	return params["s"] == strconv.Itoa(r.seq+len(data))
+}
+
+// lookupSession returns the session matching the request cookie.
+func (r *Router) lookupSession(req *http.Request) *session {
+	cookie, err := req.Cookie("JSESSIONID")
+	if err != nil {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.session == nil || r.session.id != cookie.Value {
+		return nil
+	}
+	return r.session
+}
+
+// dispatch runs the requests of a frame, stopping at the first error.
+func (r *Router) dispatch(reqs []request) ([]object, int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var result []object
+	for i, req := range reqs {
+		objects, code := r.handle(req)
+		if code != errNone {
+			return result, code
+		}
+		for _, obj := range objects {
+			obj.section = i
+			if req.method == actGet || req.method == actGL || req.method == actGS {
+				obj = obj.filter(req.names())
+			}
+			result = append(result, obj)
+		}
+	}
+	return result, errNone
+}
+
+// handle routes a request to the emulated controller.
+func (r *Router) handle(req request) ([]object, int) {
+	switch req.controller {
+	case "LTE_SMS_RECVMSGBOX", "LTE_SMS_SENDMSGBOX":
+		return r.handleBox(req)
+	case "LTE_SMS_RECVMSGENTRY", "LTE_SMS_SENDMSGENTRY":
+		return r.handleEntry(req)
+	case "LTE_SMS_SENDNEWMSG":
+		return r.handleSendNew(req)
+	case "LTE_USSD":
+		return r.handleUSSD(req)
+	case apnController:
+		return r.handleAPN(req)
+	case simController:
+		return r.handleSIM(req)
+	case "/cgi/logout":
+		return r.handleLogout(req)
+	case "/cgi/reboot":
+		return r.handleReboot(req)
+	}
+	if _, ok := r.status[req.controller]; ok {
+		if req.controller == lteLinkController {
+			return r.handleLTELink(req)
+		}
+		return r.handleStatus(req)
+	}
+	return nil, errUnknownController
+}
@@ -0,0 +1,117 @@
+package fakerouter
+
+import "strconv"
+
+// simController holds the SIM identity and PIN lock.
+const simController = "LTE_SIMLOCK"
+
+// pinAttempts is the number of PIN attempts before the PUK is required.
+const pinAttempts = 3
+
+// SIM is the SIM card of the fake router.
+type SIM struct {
+	IMSI       string
+	ICCID      string
+	PIN        string
+	PINEnabled bool
+
+	// Locked is set while the PIN is required, Attempts are the PIN
+	// attempts left. With no attempts left, the PUK is required.
+	Locked   bool
+	Attempts int
+}
+
+// SetSIM inserts a SIM. A SIM with the PIN enabled starts locked, with
+// all attempts left.
+func (r *Router) SetSIM(sim SIM) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	sim.Locked = sim.PINEnabled
+	sim.Attempts = pinAttempts
+	r.sim = &sim
+	r.updateSIMStatus()
+}
+
+// SIM returns the state of the SIM.
+func (r *Router) SIM() SIM {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.sim == nil {
+		return SIM{}
+	}
+	return *r.sim
+}
+
+// updateSIMStatus sets the simStatus of WAN_LTE_LINK_CFG.
+func (r *Router) updateSIMStatus() {
+	status := r.status[lteLinkController]
+	if status == nil {
+		status = map[string]string{}
+		r.status[lteLinkController] = status
+	}
+
+	switch {
+	case r.sim.Attempts == 0:
+		status["simStatus"] = "3"
+	case r.sim.Locked:
+		status["simStatus"] = "2"
+	default:
+		status["simStatus"] = "4"
+	}
+}
+
+// handleSIM serves LTE_SIMLOCK. A set takes an action with the PIN:
+// 1 unlocks, 2 enables and 3 disables the PIN, 4 changes it to newPin.
+func (r *Router) handleSIM(req request) ([]object, int) {
+	sim := r.sim
+	if sim == nil {
+		return nil, errUnknownController
+	}
+
+	switch req.method {
+	case actGet:
+		pinEnable := "0"
+		if sim.PINEnabled {
+			pinEnable = "1"
+		}
+		return []object{{
+			stack: req.stack,
+			attrs: map[string]string{
+				"imsi":         sim.IMSI,
+				"iccid":        sim.ICCID,
+				"pinEnable":    pinEnable,
+				"pinRemaining": strconv.Itoa(sim.Attempts),
+				"pukRemaining": "10",
+			},
+		}}, errNone
+	case actSet:
+		values := req.values()
+		action := values["action"]
+		if sim.Attempts == 0 || (action == "4" && !sim.PINEnabled) {
+			return nil, errInvalidArgument
+		}
+		if values["pin"] != sim.PIN {
+			sim.Attempts--
+			r.updateSIMStatus()
+			return nil, errInvalidArgument
+		}
+		sim.Attempts = pinAttempts
+
+		switch action {
+		case "1":
+			sim.Locked = false
+		case "2":
+			sim.PINEnabled = true
+		case "3":
+			sim.PINEnabled = false
+		case "4":
+			sim.PIN = values["newPin"]
+		default:
+			return nil, errInvalidArgument
+		}
+		r.updateSIMStatus()
+		return nil, errNone
+	}
+	return nil, errInvalidArgument
+}
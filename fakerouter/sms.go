@@ -0,0 +1,189 @@
+package fakerouter
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// pageSize is the number of entries the router returns per page.
+const pageSize = 8
+
+const timeFormat = "2006-01-02 15:04:05"
+
+// folder returns the message store and box controller for an SMS controller.
+func (r *Router) folder(controller string) (*[]*Message, string) {
+	switch controller {
+	case "LTE_SMS_RECVMSGBOX", "LTE_SMS_RECVMSGENTRY":
+		return &r.inbox, "LTE_SMS_RECVMSGBOX"
+	}
+	return &r.sent, "LTE_SMS_SENDMSGBOX"
+}
+
+// handleBox serves the page cursor and totals of an SMS folder.
+func (r *Router) handleBox(req request) ([]object, int) {
+	messages, box := r.folder(req.controller)
+
+	switch req.method {
+	case actSet:
+		page, err := strconv.Atoi(req.values()["PageNumber"])
+		if err != nil || page < 1 {
+			return nil, errInvalidArgument
+		}
+		r.pages[box] = page
+		return nil, errNone
+	case actGet:
+		page := r.pages[box]
+		if page == 0 {
+			page = 1
+		}
+		unread := 0
+		for _, msg := range *messages {
+			if msg.Unread {
+				unread++
+			}
+		}
+		return []object{{
+			stack: "0,0,0,0,0,0",
+			attrs: map[string]string{
+				"totalNumber":    strconv.Itoa(len(*messages)),
+				"amountPerPage":  strconv.Itoa(pageSize),
+				"pageNumber":     strconv.Itoa(page),
+				"unreadMessages": strconv.Itoa(unread),
+			},
+		}}, errNone
+	}
+	return nil, errInvalidArgument
+}
+
+// handleEntry serves the messages on the current page of an SMS folder.
+func (r *Router) handleEntry(req request) ([]object, int) {
+	messages, box := r.folder(req.controller)
+	page := r.currentPage(*messages, box)
+
+	switch req.method {
+	case actGL:
+		var result []object
+		for i, msg := range page {
+			result = append(result, messageObject(i+1, msg, box))
+		}
+		return result, errNone
+	case actGet:
+		pos := req.position()
+		if pos < 1 || pos > len(page) {
+			return nil, errInvalidStack
+		}
+		return []object{messageObject(pos, page[pos-1], box)}, errNone
+	case actSet:
+		pos := req.position()
+		if pos < 1 || pos > len(page) {
+			return nil, errInvalidStack
+		}
+		if v, ok := req.values()["unread"]; ok {
+			page[pos-1].Unread = v == "1"
+		}
+		return nil, errNone
+	case actDel:
+		pos := req.position()
+		if pos < 1 || pos > len(page) {
+			return nil, errInvalidStack
+		}
+		target := page[pos-1]
+		for i, msg := range *messages {
+			if msg == target {
+				*messages = append((*messages)[:i], (*messages)[i+1:]...)
+				break
+			}
+		}
+		return nil, errNone
+	}
+	return nil, errInvalidArgument
+}
+
+// handleSendNew stores an outgoing message in the sent folder.
+func (r *Router) handleSendNew(req request) ([]object, int) {
+	switch req.method {
+	case actSet:
+		values := req.values()
+		if values["to"] == "" {
+			return nil, errInvalidArgument
+		}
+		r.sent = append(r.sent, &Message{
+			Index:   r.nextIndex,
+			To:      values["to"],
+			Content: values["textContent"],
+			Time:    time.Now().UTC().Truncate(time.Second),
+		})
+		r.nextIndex++
+		return nil, errNone
+	case actGet:
+		return []object{{
+			stack: "0,0,0,0,0,0",
+			attrs: map[string]string{"sendResult": "1"},
+		}}, errNone
+	}
+	return nil, errInvalidArgument
+}
+
+// currentPage returns the messages on the selected page of a folder.
+func (r *Router) currentPage(messages []*Message, box string) []*Message {
+	page := r.pages[box]
+	if page == 0 {
+		page = 1
+	}
+
+	sorted := sortMessages(messages)
+	start := (page - 1) * pageSize
+	if start >= len(sorted) {
+		return nil
+	}
+	end := start + pageSize
+	if end > len(sorted) {
+		end = len(sorted)
+	}
+	return sorted[start:end]
+}
+
+// messageObject encodes a message as the router does for a folder.
+func messageObject(pos int, msg *Message, box string) object {
+	attrs := map[string]string{
+		"index":   strconv.Itoa(msg.Index),
+		"content": msg.Content,
+	}
+	if box == "LTE_SMS_RECVMSGBOX" {
+		attrs["from"] = msg.From
+		attrs["receivedTime"] = msg.Time.Local().Format(timeFormat)
+		attrs["unread"] = "0"
+		if msg.Unread {
+			attrs["unread"] = "1"
+		}
+	} else {
+		attrs["to"] = msg.To
+		attrs["sendTime"] = msg.Time.Local().Format(timeFormat)
+	}
+	return object{
+		stack: fmt.Sprintf("%d,0,0,0,0,0", pos),
+		attrs: attrs,
+	}
+}
+
+// sortMessages returns the messages newest first.
+func sortMessages(messages []*Message) []*Message {
+	result := append([]*Message(nil), messages...)
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Time.Equal(result[j].Time) {
+			return result[i].Index > result[j].Index
+		}
+		return result[i].Time.After(result[j].Time)
+	})
+	return result
+}
+
+func copyMessages(messages []*Message) []Message {
+	result := make([]Message, 0, len(messages))
+	for _, msg := range messages {
+		result = append(result, *msg)
+	}
+	return result
+}
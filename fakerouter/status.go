@@ -0,0 +1,46 @@
+package fakerouter
+
+// SetStatus sets the attributes of a status controller, such as
+// LTE_NET_STATUS. Gets return the attributes, sets update them.
+func (r *Router) SetStatus(controller string, attrs map[string]string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	status := map[string]string{}
+	for k, v := range attrs {
+		status[k] = v
+	}
+	r.status[controller] = status
+}
+
+// Status returns a copy of the attributes of a status controller.
+func (r *Router) Status(controller string) map[string]string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result := map[string]string{}
+	for k, v := range r.status[controller] {
+		result[k] = v
+	}
+	return result
+}
+
+// handleStatus serves a controller set with SetStatus.
+func (r *Router) handleStatus(req request) ([]object, int) {
+	status := r.status[req.controller]
+
+	switch req.method {
+	case actGet:
+		attrs := map[string]string{}
+		for k, v := range status {
+			attrs[k] = v
+		}
+		return []object{{stack: req.stack, attrs: attrs}}, errNone
+	case actSet:
+		for k, v := range req.values() {
+			status[k] = v
+		}
+		return nil, errNone
+	}
+	return nil, errInvalidArgument
+}
@@ -0,0 +1,97 @@
+package fakerouter
+
+import "strings"
+
+// USSDHandler answers a USSD session. It gets the code and the menu
+// choices sent so far, and returns the reply text and whether the
+// session stays open for another choice. A false ok fails the request.
+type USSDHandler func(session []string) (reply string, open, ok bool)
+
+// ussd is the state of the USSD session.
+type ussd struct {
+	handler USSDHandler
+	session []string
+	status  string
+	reply   string
+	open    bool
+
+	// pending delays the reply by a status poll, as on the router.
+	pending bool
+}
+
+// HandleUSSD sets the handler answering USSD requests.
+func (r *Router) HandleUSSD(handler USSDHandler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ussd = &ussd{handler: handler, status: "0"}
+}
+
+// USSDSession returns the code and choices of the current USSD session.
+func (r *Router) USSDSession() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.ussd == nil {
+		return nil
+	}
+	return append([]string(nil), r.ussd.session...)
+}
+
+// handleUSSD serves LTE_USSD: a set with action 1 sends a request, one
+// with action 2 ends the session, and a get returns the session state.
+func (r *Router) handleUSSD(req request) ([]object, int) {
+	u := r.ussd
+	if u == nil {
+		return nil, errUnknownController
+	}
+
+	switch req.method {
+	case actSet:
+		values := req.values()
+		switch values["action"] {
+		case "1":
+			content := strings.TrimSpace(values["reqContent"])
+			if content == "" {
+				return nil, errInvalidArgument
+			}
+			if !u.open {
+				u.session = nil
+			}
+			u.session = append(u.session, content)
+			u.status, u.reply, u.open, u.pending = "0", "", false, true
+		case "2":
+			u.session = nil
+			u.status, u.reply, u.open, u.pending = "0", "", false, false
+		default:
+			return nil, errInvalidArgument
+		}
+		return nil, errNone
+	case actGet:
+		if u.pending {
+			// Answer on the next poll
+			u.pending = false
+			reply, open, ok := u.handler(append([]string(nil), u.session...))
+			u.status, u.reply, u.open = "2", "", false
+			if ok {
+				u.status, u.reply, u.open = "1", reply, open
+			}
+			return []object{ussdObject("0", "", false)}, errNone
+		}
+		return []object{ussdObject(u.status, u.reply, u.open)}, errNone
+	}
+	return nil, errInvalidArgument
+}
+
+func ussdObject(status, reply string, open bool) object {
+	session := "0"
+	if open {
+		session = "1"
+	}
+	return object{
+		stack: "0,0,0,0,0,0",
+		attrs: map[string]string{
+			"ussdStatus":    status,
+			"sessionStatus": session,
+			"response":      reply,
+		},
+	}
+}
@@ -0,0 +1,277 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"gopkg.in/yaml.v3"
+
+	"github.com/titpetric/tp-link-cli/model"
+)
+
+// FormatOptions holds the command settings a formatter may use.
+type FormatOptions struct {
+	Folder   string
+	Template string
+}
+
+// Formatter writes SMS messages in an output format.
+type Formatter func(w io.Writer, opts FormatOptions, messages []model.SMSMessage) error
+
+// formatters holds the registered output formats by name.
+var formatters = map[string]Formatter{}
+
+// RegisterFormatter makes an output format available to --format.
+func RegisterFormatter(name string, f Formatter) {
+	formatters[name] = f
+}
+
+// FormatterNames returns the registered output formats, sorted.
+func FormatterNames() []string {
+	names := make([]string, 0, len(formatters))
+	for name := range formatters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GetFormatter returns the output format registered as name.
+func GetFormatter(name string) (Formatter, error) {
+	f, ok := formatters[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown format %q, available: %s", name, strings.Join(FormatterNames(), ", "))
+	}
+	return f, nil
+}
+
+func init() {
+	RegisterFormatter("table", formatTable)
+	RegisterFormatter("text", formatText)
+	RegisterFormatter("json", formatJSON)
+	RegisterFormatter("csv", formatCSV)
+	RegisterFormatter("tsv", formatTSV)
+	RegisterFormatter("yaml", formatYAML)
+	RegisterFormatter("markdown", formatMarkdown)
+	RegisterFormatter("mbox", formatMbox)
+}
+
+// formatTable formats SMS messages as a table
+func formatTable(w io.Writer, opts FormatOptions, messages []model.SMSMessage) error {
+	var headers []string
+	rows := [][]string{}
+
+	if opts.Folder == "inbox" {
+		headers = []string{"#", "ID", "Sender", "Message", "Date/Age"}
+		for i, msg := range messages {
+			rows = append(rows, []string{
+				fmt.Sprintf("%d", i+1),
+				fmt.Sprintf("%d", msg.Index),
+				msg.From,
+				msg.Content,
+				formatTime(msg.RecvTime),
+			})
+		}
+	} else {
+		headers = []string{"#", "ID", "To", "Message", "Date/Age"}
+		for i, msg := range messages {
+			rows = append(rows, []string{
+				fmt.Sprintf("%d", i+1),
+				fmt.Sprintf("%d", msg.Index),
+				msg.To,
+				msg.Content,
+				formatTime(msg.SentTime),
+			})
+		}
+	}
+
+	table := tablewriter.NewTable(
+		w,
+		tablewriter.WithHeader(headers),
+		tablewriter.WithColumnMax(180), // Set a wider column width
+	)
+
+	for _, row := range rows {
+		table.Append(row)
+	}
+
+	return table.Render()
+}
+
+// formatText formats SMS messages in a readable form, one after another
+func formatText(w io.Writer, opts FormatOptions, messages []model.SMSMessage) error {
+	if len(messages) == 0 {
+		fmt.Fprintln(w, "No message found")
+		return nil
+	}
+
+	for i, msg := range messages {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+
+		fmt.Fprintf(w, "Index: %d\n", msg.Index)
+
+		if opts.Folder == "inbox" {
+			fmt.Fprintf(w, "From: %s\n", msg.From)
+			fmt.Fprintf(w, "Received: %s\n", formatTime(msg.RecvTime))
+			fmt.Fprintf(w, "Unread: %v\n", msg.Unread)
+		} else {
+			fmt.Fprintf(w, "To: %s\n", msg.To)
+			fmt.Fprintf(w, "Sent: %s\n", formatTime(msg.SentTime))
+		}
+
+		fmt.Fprintf(w, "\nMessage:\n%s\n", msg.Content)
+	}
+	return nil
+}
+
+// formatJSON formats SMS messages as indented JSON
+func formatJSON(w io.Writer, opts FormatOptions, messages []model.SMSMessage) error {
+	data, err := json.MarshalIndent(messages, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
+
+// formatYAML formats SMS messages as a YAML list
+func formatYAML(w io.Writer, opts FormatOptions, messages []model.SMSMessage) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(messages); err != nil {
+		return fmt.Errorf("failed to marshal YAML: %w", err)
+	}
+	return enc.Close()
+}
+
+// formatCSV formats SMS messages as comma separated values
+func formatCSV(w io.Writer, opts FormatOptions, messages []model.SMSMessage) error {
+	return writeDelimited(w, ',', messages)
+}
+
+// formatTSV formats SMS messages as tab separated values
+func formatTSV(w io.Writer, opts FormatOptions, messages []model.SMSMessage) error {
+	return writeDelimited(w, '\t', messages)
+}
+
+func writeDelimited(w io.Writer, comma rune, messages []model.SMSMessage) error {
+	cw := csv.NewWriter(w)
+	cw.Comma = comma
+
+	cw.Write([]string{"index", "folder", "from", "to", "time", "unread", "content"})
+	for _, msg := range messages {
+		cw.Write([]string{
+			fmt.Sprintf("%d", msg.Index),
+			msg.Folder,
+			msg.From,
+			msg.To,
+			formatTimestamp(msg.Time()),
+			fmt.Sprintf("%v", msg.Unread),
+			msg.Content,
+		})
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// formatMarkdown formats SMS messages as a markdown table
+func formatMarkdown(w io.Writer, opts FormatOptions, messages []model.SMSMessage) error {
+	number := "To"
+	if opts.Folder == "inbox" {
+		number = "Sender"
+	}
+
+	fmt.Fprintf(w, "| # | ID | %s | Message | Date |\n", number)
+	fmt.Fprintln(w, "|---|----|--------|---------|------|")
+
+	for i, msg := range messages {
+		number := msg.To
+		if opts.Folder == "inbox" {
+			number = msg.From
+		}
+		fmt.Fprintf(w, "| %d | %d | %s | %s | %s |\n",
+			i+1,
+			msg.Index,
+			markdownEscape(number),
+			markdownEscape(msg.Content),
+			formatTimestamp(msg.Time()),
+		)
+	}
+	return nil
+}
+
+func markdownEscape(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	s = strings.ReplaceAll(s, "\r\n", "<br>")
+	return strings.ReplaceAll(s, "\n", "<br>")
+}
+
+// formatMbox formats SMS messages as an mbox, one RFC 5322 message per SMS
+func formatMbox(w io.Writer, opts FormatOptions, messages []model.SMSMessage) error {
+	for _, msg := range messages {
+		from, to := smsAddress(msg.From), smsAddress("router")
+		if msg.Folder == "sent" || (msg.Folder == "" && opts.Folder == "sent") {
+			from, to = smsAddress("router"), smsAddress(msg.To)
+		}
+
+		date := msg.Time()
+		if date.IsZero() {
+			date = time.Unix(0, 0)
+		}
+
+		hash := msg.Hash
+		if hash == "" {
+			hash = msg.ContentHash()
+		}
+
+		subject, _, _ := strings.Cut(msg.Content, "\n")
+		subject = truncate(subject, 60)
+
+		fmt.Fprintf(w, "From %s %s\n", from, date.UTC().Format(time.ANSIC))
+		fmt.Fprintf(w, "From: %s\n", from)
+		fmt.Fprintf(w, "To: %s\n", to)
+		fmt.Fprintf(w, "Date: %s\n", date.Format(time.RFC1123Z))
+		fmt.Fprintf(w, "Subject: %s\n", mime.QEncoding.Encode("utf-8", subject))
+		fmt.Fprintf(w, "Message-ID: <%s@sms.local>\n", hash)
+		fmt.Fprintln(w, "MIME-Version: 1.0")
+		fmt.Fprintln(w, "Content-Type: text/plain; charset=utf-8")
+		fmt.Fprintln(w, "Content-Transfer-Encoding: 8bit")
+		fmt.Fprintln(w)
+
+		for _, line := range strings.Split(msg.Content, "\n") {
+			// mboxrd quoting, so body lines are not read as separators
+			if strings.HasPrefix(strings.TrimLeft(line, ">"), "From ") {
+				line = ">" + line
+			}
+			fmt.Fprintln(w, line)
+		}
+		fmt.Fprintln(w)
+	}
+	return nil
+}
+
+// smsAddress returns the email address used for a phone number.
+func smsAddress(number string) string {
+	if number == "" {
+		number = "unknown"
+	}
+	return number + "@sms.local"
+}
+
+// formatTimestamp formats a time as RFC 3339, or empty if unset
+func formatTimestamp(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
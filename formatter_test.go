@@ -0,0 +1,148 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"mime"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/titpetric/tp-link-cli/model"
+)
+
+func testMessages() []model.SMSMessage {
+	received := time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC)
+	return []model.SMSMessage{
+		{
+			Index:    3,
+			From:     "+38640111222",
+			Content:  "Dobrodošli | BRZINA\nFrom now on, reply",
+			RecvTime: received,
+			Unread:   true,
+			Folder:   "inbox",
+			Host:     "192.168.1.1",
+		},
+		{
+			Index:    2,
+			From:     "13909",
+			Content:  "hello",
+			RecvTime: received.Add(-time.Hour),
+			Folder:   "inbox",
+			Host:     "192.168.1.1",
+		},
+	}
+}
+
+func format(t *testing.T, name string, messages []model.SMSMessage) string {
+	t.Helper()
+
+	formatter, err := GetFormatter(name)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, formatter(&buf, FormatOptions{Folder: "inbox"}, messages))
+	return buf.String()
+}
+
+func TestGetFormatterUnknown(t *testing.T) {
+	_, err := GetFormatter("xml")
+	assert.ErrorContains(t, err, "csv")
+}
+
+func TestParseArgsFormat(t *testing.T) {
+	cmd, _, err := ParseArgs([]string{"list", "--format=csv"})
+	require.NoError(t, err)
+	assert.Equal(t, "csv", cmd.Format)
+
+	_, _, err = ParseArgs([]string{"list", "--format=xml"})
+	assert.Error(t, err)
+}
+
+func TestCheckFormat(t *testing.T) {
+	cmd, _, err := ParseArgs([]string{"status", "--format=csv"})
+	require.NoError(t, err)
+	assert.ErrorContains(t, cmd.CheckFormat(valueFormats...), `format "csv" is not supported for this command, use table, json, yaml`)
+
+	cmd, _, err = ParseArgs([]string{"status", "--format=yaml"})
+	require.NoError(t, err)
+	assert.NoError(t, cmd.CheckFormat(valueFormats...))
+
+	cmd, _, err = ParseArgs([]string{"status"})
+	require.NoError(t, err)
+	assert.NoError(t, cmd.CheckFormat(valueFormats...))
+}
+
+func TestFormatCSV(t *testing.T) {
+	out := format(t, "csv", testMessages())
+
+	records, err := csv.NewReader(strings.NewReader(out)).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+	assert.Equal(t, []string{"index", "folder", "from", "to", "time", "unread", "content"}, records[0])
+	assert.Equal(t, "2026-03-04T05:06:07Z", records[1][4])
+	assert.Equal(t, "Dobrodošli | BRZINA\nFrom now on, reply", records[1][6])
+}
+
+func TestFormatTSV(t *testing.T) {
+	out := format(t, "tsv", testMessages()[1:])
+	assert.Contains(t, out, "2\tinbox\t13909\t\t")
+}
+
+func TestFormatYAML(t *testing.T) {
+	out := format(t, "yaml", testMessages()[1:])
+	assert.Contains(t, out, "- index: 2\n")
+	assert.Contains(t, out, "  from: \"13909\"\n")
+	assert.Contains(t, out, "  receivedTime: 2026-03-04T04:06:07Z\n")
+	assert.NotContains(t, out, "sendTime")
+}
+
+func TestFormatMarkdown(t *testing.T) {
+	out := format(t, "markdown", testMessages())
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	require.Len(t, lines, 4)
+	assert.Equal(t, "| # | ID | Sender | Message | Date |", lines[0])
+	assert.Contains(t, lines[2], `Dobrodošli \| BRZINA<br>From now on, reply`)
+}
+
+func TestFormatMbox(t *testing.T) {
+	out := format(t, "mbox", testMessages())
+
+	parts := strings.Split(out, "\n\nFrom ")
+	require.Len(t, parts, 2)
+	assert.True(t, strings.HasPrefix(out, "From +38640111222@sms.local Wed Mar  4 05:06:07 2026\n"))
+
+	// Each message, without the separator line, is a valid RFC 5322 message
+	_, first, _ := strings.Cut(parts[0], "\n")
+	msg, err := mail.ReadMessage(strings.NewReader(first))
+	require.NoError(t, err)
+	assert.Equal(t, "+38640111222@sms.local", msg.Header.Get("From"))
+	assert.Equal(t, "router@sms.local", msg.Header.Get("To"))
+
+	date, err := msg.Header.Date()
+	require.NoError(t, err)
+	assert.True(t, date.Equal(testMessages()[0].RecvTime))
+
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	require.NoError(t, err)
+	assert.Equal(t, "Dobrodošli | BRZINA", subject)
+
+	assert.Contains(t, first, "\n>From now on, reply")
+}
+
+func TestFormatText(t *testing.T) {
+	out := format(t, "text", testMessages()[1:])
+	assert.Contains(t, out, "From: 13909\n")
+	assert.Contains(t, out, "\nMessage:\nhello\n")
+
+	assert.Equal(t, "No message found\n", format(t, "text", nil))
+}
+
+func TestTruncate(t *testing.T) {
+	assert.Equal(t, "short", truncate("short", 10))
+	assert.Equal(t, "Dobrodošl...", truncate("Dobrodošli", 9))
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+	"os"
+	"strings"
+
+	"github.com/titpetric/tp-link-cli/email"
+)
+
+// ForwardEmail polls the inbox and relays each new message as an email
+// to --mail-to, deleting it from the router with --delete.
+func (c *SMSCommand) ForwardEmail(ctx context.Context) error {
+	if c.SMTP == "" {
+		return fmt.Errorf("forward-email requires --smtp=<host:port>")
+	}
+	if len(c.MailTo) == 0 {
+		return fmt.Errorf("forward-email requires --mail-to=<address>")
+	}
+
+	forwarder := &email.Forwarder{
+		Addr: c.SMTP,
+		From: c.MailFrom,
+		To:   c.MailTo,
+		Logf: func(format string, args ...interface{}) {
+			fmt.Fprintf(os.Stderr, format+"\n", args...)
+		},
+	}
+	if c.SMTPAuth != "" {
+		username, password, ok := strings.Cut(c.SMTPAuth, ":")
+		if !ok {
+			return fmt.Errorf("invalid smtp auth, expected <user:pass>")
+		}
+		host, _, _ := net.SplitHostPort(c.SMTP)
+		forwarder.Auth = smtp.PlainAuth("", username, password, host)
+	}
+
+	smsClient, err := c.NewClient()
+	if err != nil {
+		return err
+	}
+	if c.Delete {
+		forwarder.Deleter = smsClient
+	}
+	return c.runWatchClient(ctx, smsClient, forwarder.Handle)
+}
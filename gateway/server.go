@@ -0,0 +1,233 @@
+// Package gateway implements an SMS gateway REST API over the router client.
+package gateway
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/titpetric/tp-link-cli/client"
+	"github.com/titpetric/tp-link-cli/model"
+)
+
+// Server serves the SMS gateway API:
+//
+//	GET    /sms/{folder}       list messages (?page=N, or ?all=true)
+//	GET    /sms/{folder}/{id}  get a message by index
+//	DELETE /sms/{folder}/{id}  delete a message by index
+//	POST   /sms                send a message, {"to": "...", "content": "..."}
+//
+// All requests share a single router session. The router sequence and
+// session are not safe for concurrent use, so requests are serialised.
+type Server struct {
+	client *client.SMSClient
+	token  string
+
+	mu  sync.Mutex
+	mux *http.ServeMux
+}
+
+// NewServer creates a gateway for the client. Requests must carry
+// the token as a bearer token in the Authorization header.
+func NewServer(c *client.SMSClient, token string) *Server {
+	s := &Server{
+		client: c,
+		token:  token,
+		mux:    http.NewServeMux(),
+	}
+
+	s.mux.HandleFunc("GET /sms/{folder}", s.handleList)
+	s.mux.HandleFunc("GET /sms/{folder}/{id}", s.handleGet)
+	s.mux.HandleFunc("DELETE /sms/{folder}/{id}", s.handleDelete)
+	s.mux.HandleFunc("POST /sms", s.handleSend)
+
+	return s
+}
+
+// ServeHTTP authenticates the request and routes it to the handlers.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !s.authorized(r) {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="tp-link-cli"`)
+		writeError(w, http.StatusUnauthorized, errors.New("unauthorized"))
+		return
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) authorized(r *http.Request) bool {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || s.token == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) == 1
+}
+
+func (s *Server) handleList(w http.ResponseWriter, r *http.Request) {
+	folder, ok := parseFolder(w, r)
+	if !ok {
+		return
+	}
+
+	all, _ := strconv.ParseBool(r.URL.Query().Get("all"))
+	page := 1
+	if v := r.URL.Query().Get("page"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid page: %s", v))
+			return
+		}
+		page = n
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var resp *model.ListResponse
+	var err error
+	if all {
+		resp, err = s.client.ListAll(r.Context(), folder)
+	} else {
+		resp, err = s.client.ListPage(r.Context(), folder, page)
+	}
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err)
+		return
+	}
+	if resp.Data == nil {
+		resp.Data = []model.SMSMessage{}
+	}
+	writeResponse(w, resp.Error, resp)
+}
+
+func (s *Server) handleGet(w http.ResponseWriter, r *http.Request) {
+	folder, ok := parseFolder(w, r)
+	if !ok {
+		return
+	}
+	id, ok := parseID(w, r)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	msg, _, _, err := s.client.Find(r.Context(), folder, id)
+	if err != nil {
+		writeClientError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, &model.ReadResponse{
+		Data: []model.SMSMessage{*msg},
+	})
+}
+
+func (s *Server) handleDelete(w http.ResponseWriter, r *http.Request) {
+	folder, ok := parseFolder(w, r)
+	if !ok {
+		return
+	}
+	id, ok := parseID(w, r)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	resp, err := s.client.DeleteByIndex(r.Context(), folder, id)
+	if err != nil {
+		writeClientError(w, err)
+		return
+	}
+	if resp.Data == nil {
+		resp.Data = []model.SMSMessage{}
+	}
+	writeResponse(w, resp.Error, resp)
+}
+
+func (s *Server) handleSend(w http.ResponseWriter, r *http.Request) {
+	var req model.SendRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64*1024)).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+	if req.To == "" || req.Content == "" {
+		writeError(w, http.StatusBadRequest, errors.New("to and content are required"))
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	resp, err := s.client.Send(r.Context(), req.To, req.Content)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err)
+		return
+	}
+	if resp.Data == nil {
+		resp.Data = []model.SMSMessage{}
+	}
+	writeResponse(w, resp.Error, resp)
+}
+
+// Logout ends the router session once no request is in flight.
+func (s *Server) Logout(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.client.Logout(ctx)
+}
+
+func parseFolder(w http.ResponseWriter, r *http.Request) (string, bool) {
+	folder := r.PathValue("folder")
+	if folder != "inbox" && folder != "sent" {
+		writeError(w, http.StatusNotFound, fmt.Errorf("invalid folder: %s", folder))
+		return "", false
+	}
+	return folder, true
+}
+
+func parseID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid id: %s", r.PathValue("id")))
+		return 0, false
+	}
+	return id, true
+}
+
+// writeResponse writes a router response, a router error code is a bad gateway.
+func writeResponse(w http.ResponseWriter, code int, v interface{}) {
+	status := http.StatusOK
+	if code != 0 {
+		status = http.StatusBadGateway
+	}
+	writeJSON(w, status, v)
+}
+
+func writeClientError(w http.ResponseWriter, err error) {
+	if errors.Is(err, client.ErrNotFound) {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	writeError(w, http.StatusBadGateway, err)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, &model.ErrorResponse{
+		Error:   status,
+		Message: err.Error(),
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
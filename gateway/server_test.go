@@ -0,0 +1,160 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/titpetric/tp-link-cli/client"
+	"github.com/titpetric/tp-link-cli/fakerouter"
+	"github.com/titpetric/tp-link-cli/model"
+)
+
+const testToken = "s3cret"
+
+func newTestServer(t *testing.T) (*httptest.Server, *fakerouter.Router) {
+	t.Helper()
+
+	router := fakerouter.New("admin", "secret")
+	t.Cleanup(router.Close)
+
+	c, err := client.NewSMSClient(&client.Options{
+		Auth: "admin:secret",
+		Host: router.URL(),
+	})
+	require.NoError(t, err)
+
+	server := httptest.NewServer(NewServer(c, testToken))
+	t.Cleanup(server.Close)
+	return server, router
+}
+
+func do(t *testing.T, server *httptest.Server, method, path, body string, out interface{}) int {
+	t.Helper()
+
+	req, err := http.NewRequest(method, server.URL+path, strings.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+testToken)
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	if out != nil {
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(out))
+	}
+	return resp.StatusCode
+}
+
+func TestUnauthorized(t *testing.T) {
+	server, _ := newTestServer(t)
+
+	resp, err := http.Get(server.URL + "/sms/inbox")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	req, _ := http.NewRequest("GET", server.URL+"/sms/inbox", nil)
+	req.Header.Set("Authorization", "Bearer wrong")
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
+
+func TestListAndGet(t *testing.T) {
+	server, router := newTestServer(t)
+	now := time.Now()
+	for i := 0; i < 10; i++ {
+		router.Receive("+38640111222", "message", now.Add(time.Duration(i)*time.Minute))
+	}
+
+	var list model.ListResponse
+	assert.Equal(t, http.StatusOK, do(t, server, "GET", "/sms/inbox", "", &list))
+	assert.Len(t, list.Data, 8)
+
+	assert.Equal(t, http.StatusOK, do(t, server, "GET", "/sms/inbox?page=2", "", &list))
+	assert.Len(t, list.Data, 2)
+
+	assert.Equal(t, http.StatusOK, do(t, server, "GET", "/sms/inbox?all=true", "", &list))
+	assert.Len(t, list.Data, 10)
+
+	var read model.ReadResponse
+	assert.Equal(t, http.StatusOK, do(t, server, "GET", "/sms/inbox/1", "", &read))
+	require.Len(t, read.Data, 1)
+	assert.Equal(t, 1, read.Data[0].Index)
+
+	var errResp model.ErrorResponse
+	assert.Equal(t, http.StatusNotFound, do(t, server, "GET", "/sms/inbox/99", "", &errResp))
+	assert.Contains(t, errResp.Message, "not found")
+
+	assert.Equal(t, http.StatusNotFound, do(t, server, "GET", "/sms/drafts", "", &errResp))
+	assert.Equal(t, http.StatusBadRequest, do(t, server, "GET", "/sms/inbox/abc", "", &errResp))
+	assert.Equal(t, http.StatusBadRequest, do(t, server, "GET", "/sms/inbox?page=0", "", &errResp))
+}
+
+func TestDelete(t *testing.T) {
+	server, router := newTestServer(t)
+	index := router.Receive("+38640111222", "hello", time.Now())
+
+	var resp model.DeleteResponse
+	assert.Equal(t, http.StatusOK, do(t, server, "DELETE", "/sms/inbox/"+strconv.Itoa(index), "", &resp))
+	assert.Empty(t, router.Inbox())
+}
+
+func TestSend(t *testing.T) {
+	server, router := newTestServer(t)
+
+	var resp model.SendResponse
+	body := `{"to": "13909", "content": "brzina"}`
+	assert.Equal(t, http.StatusOK, do(t, server, "POST", "/sms", body, &resp))
+
+	sent := router.Sent()
+	require.Len(t, sent, 1)
+	assert.Equal(t, "brzina", sent[0].Content)
+
+	var errResp model.ErrorResponse
+	assert.Equal(t, http.StatusBadRequest, do(t, server, "POST", "/sms", `{"to": "13909"}`, &errResp))
+	assert.Equal(t, http.StatusBadRequest, do(t, server, "POST", "/sms", `not json`, &errResp))
+}
+
+func TestConcurrentRequests(t *testing.T) {
+	server, router := newTestServer(t)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			body, _ := json.Marshal(model.SendRequest{To: "13909", Content: "hello"})
+			assert.Equal(t, http.StatusOK, do(t, server, "POST", "/sms", string(body), nil))
+		}()
+	}
+	wg.Wait()
+
+	assert.Len(t, router.Sent(), 10)
+	assert.Equal(t, 1, router.Logins())
+}
+
+func TestLogout(t *testing.T) {
+	router := fakerouter.New("admin", "secret")
+	defer router.Close()
+
+	c, err := client.NewSMSClient(&client.Options{Auth: "admin:secret", Host: router.URL()})
+	require.NoError(t, err)
+
+	server := NewServer(c, testToken)
+	require.NoError(t, c.Connect(context.Background()))
+	require.NoError(t, server.Logout(context.Background()))
+	assert.False(t, router.LoggedIn())
+}
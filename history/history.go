@@ -0,0 +1,214 @@
+// Package history records LTE signal samples to a rolling history file
+// and summarizes them.
+//
+// A history file ending in .csv is written as CSV with a header row,
+// any other as JSON lines holding one model.SignalSample each.
+package history
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/titpetric/tp-link-cli/model"
+)
+
+// DefaultKeep is the number of samples kept in a history file, a day
+// of samples at a 10 second interval.
+const DefaultKeep = 8640
+
+// csvHeader names the columns of a CSV history file.
+var csvHeader = []string{
+	"time", "connection", "network", "operator", "level",
+	"rsrp", "rsrq", "snr", "rssi", "band", "channel", "cell_id", "error",
+}
+
+// Recorder appends samples to a history file. Once the file holds more
+// than Keep samples, it is rewritten with the last Keep. The file is
+// trimmed in batches, so it is not rewritten for every sample.
+type Recorder struct {
+	Path string
+	Keep int
+
+	csv     bool
+	samples int
+}
+
+// NewRecorder returns a recorder appending to path, counting the
+// samples already in the file. A keep of zero keeps DefaultKeep.
+func NewRecorder(path string, keep int) (*Recorder, error) {
+	if keep <= 0 {
+		keep = DefaultKeep
+	}
+	r := &Recorder{
+		Path: path,
+		Keep: keep,
+		csv:  strings.EqualFold(filepath.Ext(path), ".csv"),
+	}
+
+	_, samples, err := r.readSamples()
+	if err != nil {
+		return nil, err
+	}
+	r.samples = len(samples)
+	return r, nil
+}
+
+// Append writes a sample to the end of the file, and trims the file if
+// it holds too many samples.
+func (r *Recorder) Append(sample model.SignalSample) error {
+	if err := os.MkdirAll(filepath.Dir(r.Path), 0o755); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(r.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+
+	var line []byte
+	if r.csv {
+		var rows [][]string
+		if info, err := f.Stat(); err == nil && info.Size() == 0 {
+			rows = append(rows, csvHeader)
+		}
+		line, err = encodeCSV(append(rows, csvRecord(sample))...)
+	} else {
+		line, err = json.Marshal(sample)
+		line = append(line, '\n')
+	}
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	if _, err := f.Write(line); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	r.samples++
+	if r.samples > r.Keep+max(r.Keep/10, 1) {
+		return r.trim()
+	}
+	return nil
+}
+
+// trim replaces the file with its last Keep samples.
+func (r *Recorder) trim() error {
+	header, samples, err := r.readSamples()
+	if err != nil {
+		return err
+	}
+	if len(samples) > r.Keep {
+		samples = samples[len(samples)-r.Keep:]
+	}
+
+	body := append([]byte{}, header...)
+	for _, sample := range samples {
+		body = append(body, sample...)
+	}
+
+	tmp := r.Path + ".tmp"
+	if err := os.WriteFile(tmp, body, 0o600); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, r.Path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	r.samples = len(samples)
+	return nil
+}
+
+// readSamples returns the encoded samples of the file, each ending in a
+// newline, and the CSV header row. CSV is read by record, as a quoted
+// error may span several lines. A missing file has no samples.
+func (r *Recorder) readSamples() ([]byte, [][]byte, error) {
+	f, err := os.Open(r.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	var samples [][]byte
+	if !r.csv {
+		scanner := bufio.NewScanner(f)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			if len(scanner.Bytes()) > 0 {
+				samples = append(samples, append(scanner.Bytes(), '\n'))
+			}
+		}
+		return nil, samples, scanner.Err()
+	}
+
+	reader := csv.NewReader(f)
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", r.Path, err)
+	}
+	if len(records) == 0 {
+		return nil, nil, nil
+	}
+
+	header, err := encodeCSV(records[0])
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, record := range records[1:] {
+		sample, err := encodeCSV(record)
+		if err != nil {
+			return nil, nil, err
+		}
+		samples = append(samples, sample)
+	}
+	return header, samples, nil
+}
+
+func csvRecord(s model.SignalSample) []string {
+	return []string{
+		s.Time.Format(time.RFC3339),
+		s.Connection,
+		s.NetworkType,
+		s.Operator,
+		strconv.Itoa(s.Level),
+		formatFloat(s.RSRP),
+		formatFloat(s.RSRQ),
+		formatFloat(s.SNR),
+		formatFloat(s.RSSI),
+		s.Band,
+		s.Channel,
+		s.CellID,
+		s.Error,
+	}
+}
+
+func encodeCSV(records ...[]string) ([]byte, error) {
+	var sb strings.Builder
+	w := csv.NewWriter(&sb)
+	if err := w.WriteAll(records); err != nil {
+		return nil, err
+	}
+	return []byte(sb.String()), nil
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
@@ -0,0 +1,133 @@
+package history
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/titpetric/tp-link-cli/model"
+)
+
+func sample(at time.Time, rsrp float64, connected bool) model.SignalSample {
+	s := model.SignalSample{Time: at}
+	s.Connected = connected
+	s.Connection = "connected"
+	if !connected {
+		s.Connection = "disconnected"
+	}
+	s.RSRP = rsrp
+	s.SNR = 10
+	return s
+}
+
+func TestRecorderJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "signal.jsonl")
+	start := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	r, err := NewRecorder(path, 10)
+	require.NoError(t, err)
+	for i := 0; i < 12; i++ {
+		require.NoError(t, r.Append(sample(start.Add(time.Duration(i)*time.Second), float64(-90-i), true)))
+	}
+
+	// One past the trim margin rewrites the file with the last 10
+	_, lines, err := r.readSamples()
+	require.NoError(t, err)
+	require.Len(t, lines, 10)
+
+	var first model.SignalSample
+	require.NoError(t, json.Unmarshal(lines[0], &first))
+	assert.Equal(t, -92.0, first.RSRP)
+	assert.True(t, first.Time.Equal(start.Add(2*time.Second)))
+
+	// A new recorder counts the existing samples
+	r, err = NewRecorder(path, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 10, r.samples)
+}
+
+func TestRecorderCSV(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "signal.csv")
+	start := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	r, err := NewRecorder(path, 2)
+	require.NoError(t, err)
+	for i := 0; i < 4; i++ {
+		require.NoError(t, r.Append(sample(start.Add(time.Duration(i)*time.Second), float64(-90-i), true)))
+	}
+	failed := model.SignalSample{Time: start.Add(time.Minute), Error: "connection refused"}
+	require.NoError(t, r.Append(failed))
+
+	body, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(body)), "\n")
+	// Trimmed to 2 samples at the fourth, with the failed one appended
+	require.Len(t, lines, 4)
+	assert.Equal(t, strings.Join(csvHeader, ","), lines[0])
+	assert.Equal(t, "2026-01-02T03:04:07Z,connected,,,0,-92,0,10,0,,,,", lines[1])
+	assert.Equal(t, "2026-01-02T03:05:05Z,,,,0,0,0,0,0,,,,connection refused", lines[3])
+}
+
+func TestRecorderCSVMultilineError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "signal.csv")
+	start := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	r, err := NewRecorder(path, 2)
+	require.NoError(t, err)
+	for i := 0; i < 4; i++ {
+		failed := model.SignalSample{
+			Time:  start.Add(time.Duration(i) * time.Second),
+			Error: fmt.Sprintf("login failed %d\nStatus: 500\nResponse: busy", i),
+		}
+		require.NoError(t, r.Append(failed))
+	}
+
+	// The quoted errors span lines, trimming keeps whole records
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	records, err := csv.NewReader(f).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+	assert.Equal(t, csvHeader, records[0])
+	assert.Equal(t, "login failed 2\nStatus: 500\nResponse: busy", records[1][len(csvHeader)-1])
+
+	r, err = NewRecorder(path, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 2, r.samples)
+}
+
+func TestSummary(t *testing.T) {
+	start := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	var s Summary
+	s.Add(sample(start, -90, true))
+	s.Add(sample(start.Add(10*time.Second), -100, true))
+	s.Add(sample(start.Add(20*time.Second), 0, false))
+	s.Add(model.SignalSample{Time: start.Add(30 * time.Second), Error: "timeout"})
+	s.Add(sample(start.Add(40*time.Second), -95, true))
+
+	assert.Equal(t, 5, s.Samples)
+	assert.Equal(t, 1, s.Failed)
+	assert.Equal(t, 3, s.Connected)
+	assert.Equal(t, 1, s.Outages)
+	assert.Equal(t, -100.0, s.RSRP.Min)
+	assert.Equal(t, -95.0, s.RSRP.Avg)
+	assert.Equal(t, -90.0, s.RSRP.Max)
+	assert.Equal(t, 3, s.RSRP.Count)
+
+	var buf bytes.Buffer
+	_, err := s.WriteTo(&buf)
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), "5 samples over 40s, 1 failed, connected 60.0%, 1 outages\n")
+	assert.Contains(t, buf.String(), "RSRP  min -100  avg -95.0  max -90 dBm\n")
+}
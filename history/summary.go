@@ -0,0 +1,118 @@
+package history
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/titpetric/tp-link-cli/model"
+)
+
+// Stats holds the minimum, average and maximum of a signal value.
+type Stats struct {
+	Min   float64 `json:"min"`
+	Avg   float64 `json:"avg"`
+	Max   float64 `json:"max"`
+	Count int     `json:"count"`
+
+	sum float64
+}
+
+func (s *Stats) add(v float64) {
+	if s.Count == 0 || v < s.Min {
+		s.Min = v
+	}
+	if s.Count == 0 || v > s.Max {
+		s.Max = v
+	}
+	s.Count++
+	s.sum += v
+	s.Avg = s.sum / float64(s.Count)
+}
+
+// Summary accumulates the samples of a monitoring session. Signal
+// values are only taken from samples which were connected, as the
+// router reports zeroes without service.
+type Summary struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+
+	Samples   int `json:"samples"`
+	Failed    int `json:"failed"`
+	Connected int `json:"connected"`
+
+	// Outages counts the times the connection was lost between samples.
+	Outages int `json:"outages"`
+
+	RSRP Stats `json:"rsrp"`
+	RSRQ Stats `json:"rsrq"`
+	SNR  Stats `json:"snr"`
+	RSSI Stats `json:"rssi"`
+
+	connected bool
+}
+
+// Add records a sample.
+func (s *Summary) Add(sample model.SignalSample) {
+	if s.Samples == 0 {
+		s.Start = sample.Time
+	}
+	s.End = sample.Time
+	s.Samples++
+
+	connected := sample.Error == "" && sample.Connected
+	if sample.Error != "" {
+		s.Failed++
+	}
+	if s.connected && !connected {
+		s.Outages++
+	}
+	s.connected = connected
+	if !connected {
+		return
+	}
+
+	s.Connected++
+	s.RSRP.add(sample.RSRP)
+	s.RSRQ.add(sample.RSRQ)
+	s.SNR.add(sample.SNR)
+	s.RSSI.add(sample.RSSI)
+}
+
+// WriteTo writes the summary as text.
+func (s *Summary) WriteTo(w io.Writer) (int64, error) {
+	var n int64
+	printf := func(format string, args ...interface{}) error {
+		m, err := fmt.Fprintf(w, format, args...)
+		n += int64(m)
+		return err
+	}
+
+	uptime := 0.0
+	if s.Samples > 0 {
+		uptime = 100 * float64(s.Connected) / float64(s.Samples)
+	}
+	if err := printf("%d samples over %s, %d failed, connected %.1f%%, %d outages\n",
+		s.Samples, s.End.Sub(s.Start).Round(time.Second), s.Failed, uptime, s.Outages); err != nil {
+		return n, err
+	}
+
+	if s.Connected == 0 {
+		return n, nil
+	}
+	for _, row := range []struct {
+		name, unit string
+		stats      Stats
+	}{
+		{"RSRP", "dBm", s.RSRP},
+		{"RSRQ", "dB", s.RSRQ},
+		{"SINR", "dB", s.SNR},
+		{"RSSI", "dBm", s.RSSI},
+	} {
+		if err := printf("%-5s min %s  avg %s  max %s %s\n", row.name,
+			formatFloat(row.stats.Min), fmt.Sprintf("%.1f", row.stats.Avg), formatFloat(row.stats.Max), row.unit); err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
@@ -0,0 +1,258 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"gopkg.in/yaml.v3"
+
+	"github.com/titpetric/tp-link-cli/history"
+	"github.com/titpetric/tp-link-cli/model"
+)
+
+// defaultMonitorInterval is the lte monitor sampling interval
+const defaultMonitorInterval = 10 * time.Second
+
+// LTE connect and disconnect wait defaults
+const (
+	defaultLTETimeout  = time.Minute
+	defaultLTEInterval = time.Second
+)
+
+// LTEStatus prints the LTE connection state, operator and signal
+func (c *SMSCommand) LTEStatus(ctx context.Context) error {
+	smsClient, err := c.NewClient()
+	if err != nil {
+		return err
+	}
+	defer c.CloseClient(ctx, smsClient)
+
+	status, err := smsClient.LTEStatus(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get LTE status: %w", err)
+	}
+
+	return c.outputValue(os.Stdout, status, lteStatusRows(status))
+}
+
+// LTEMonitor samples the LTE status over one router session until
+// interrupted, printing each sample and a min/avg/max summary on exit.
+// With --out, the samples are also kept in a rolling history file.
+func (c *SMSCommand) LTEMonitor(ctx context.Context) error {
+	var recorder *history.Recorder
+	if c.Out != "" {
+		var err error
+		recorder, err = history.NewRecorder(c.Out, c.Keep)
+		if err != nil {
+			return fmt.Errorf("failed to open history: %w", err)
+		}
+	}
+
+	smsClient, err := c.NewClient()
+	if err != nil {
+		return err
+	}
+	// Log out with a fresh context, as ctx is cancelled on interrupt
+	defer c.CloseClient(context.Background(), smsClient)
+
+	interval := c.Interval
+	if interval == 0 {
+		interval = defaultMonitorInterval
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	enc := json.NewEncoder(os.Stdout)
+	asJSON := c.JSON || c.Format == "json"
+	summary := &history.Summary{}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+loop:
+	for {
+		sample := model.SignalSample{Time: time.Now()}
+		status, err := smsClient.LTEStatus(ctx)
+		if ctx.Err() != nil {
+			break
+		}
+		if err != nil {
+			sample.Error = err.Error()
+		} else {
+			sample.LTEStatus = *status
+		}
+		summary.Add(sample)
+
+		if asJSON {
+			enc.Encode(sample)
+		} else {
+			fmt.Println(formatSample(sample))
+		}
+		if recorder != nil {
+			if err := recorder.Append(sample); err != nil {
+				fmt.Fprintf(os.Stderr, "warning: failed to write history: %v\n", err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			break loop
+		case <-ticker.C:
+		}
+	}
+
+	if asJSON {
+		return enc.Encode(summary)
+	}
+	fmt.Println()
+	_, err = summary.WriteTo(os.Stdout)
+	return err
+}
+
+// formatSample returns a monitor sample as a line of text
+func formatSample(s model.SignalSample) string {
+	at := s.Time.Format("15:04:05")
+	if s.Error != "" {
+		return at + " error: " + s.Error
+	}
+	if !s.Connected {
+		return fmt.Sprintf("%s %s %s", at, s.Connection, s.NetworkType)
+	}
+	return fmt.Sprintf("%s %s %s %d/5 RSRP %s dBm RSRQ %s dB SINR %s dB RSSI %s dBm",
+		at, s.Connection, s.NetworkType, s.Level,
+		formatFloat(s.RSRP), formatFloat(s.RSRQ), formatFloat(s.SNR), formatFloat(s.RSSI))
+}
+
+// LTEConnect connects, disconnects or reconnects the LTE data connection,
+// waiting for the connection to settle, and reports the public IP
+func (c *SMSCommand) LTEConnect(ctx context.Context, action string) error {
+	smsClient, err := c.NewClient()
+	if err != nil {
+		return err
+	}
+	defer c.CloseClient(ctx, smsClient)
+
+	timeout := c.Timeout
+	if timeout == 0 {
+		timeout = defaultLTETimeout
+	}
+	interval := c.Interval
+	if interval == 0 {
+		interval = defaultLTEInterval
+	}
+
+	var steps []bool
+	switch action {
+	case "connect":
+		steps = []bool{true}
+	case "disconnect":
+		steps = []bool{false}
+	case "reconnect":
+		steps = []bool{false, true}
+	default:
+		return fmt.Errorf("unknown action: %s", action)
+	}
+
+	previous, err := smsClient.LTEStatus(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get LTE status: %w", err)
+	}
+
+	status := previous
+	for _, connected := range steps {
+		if err := smsClient.SetLTEEnabled(ctx, connected); err != nil {
+			return fmt.Errorf("failed to %s: %w", action, err)
+		}
+
+		waitCtx, cancel := context.WithTimeout(ctx, timeout)
+		status, err = smsClient.WaitLTE(waitCtx, connected, interval)
+		cancel()
+		if err != nil {
+			return fmt.Errorf("failed to %s: %w", action, err)
+		}
+	}
+
+	if c.JSON || c.Format != "" {
+		return c.outputValue(os.Stdout, status, lteStatusRows(status))
+	}
+	if !status.Connected {
+		fmt.Printf("LTE %s\n", status.Connection)
+		return nil
+	}
+	if previous.IP != "" && previous.IP != status.IP {
+		fmt.Printf("LTE connected, public IP %s (was %s)\n", status.IP, previous.IP)
+		return nil
+	}
+	fmt.Printf("LTE connected, public IP %s\n", status.IP)
+	return nil
+}
+
+// lteStatusRows returns the status as field, value rows
+func lteStatusRows(s *model.LTEStatus) [][]string {
+	return [][]string{
+		{"Connection", s.Connection},
+		{"Enabled", strconv.FormatBool(s.Enabled)},
+		{"Operator", s.Operator},
+		{"Network", s.NetworkType},
+		{"Roaming", strconv.FormatBool(s.Roaming)},
+		{"SIM", s.SIMStatus},
+		{"IP", s.IP},
+		{"Signal", fmt.Sprintf("%d/5", s.Level)},
+		{"RSRP", formatFloat(s.RSRP) + " dBm"},
+		{"RSRQ", formatFloat(s.RSRQ) + " dB"},
+		{"SINR", formatFloat(s.SNR) + " dB"},
+		{"RSSI", formatFloat(s.RSSI) + " dBm"},
+		{"Band", s.Band},
+		{"Channel", s.Channel},
+		{"Cell ID", s.CellID},
+	}
+}
+
+// outputValue writes a value as JSON or YAML with --json or --format,
+// or the rows as a field, value table
+func (c *SMSCommand) outputValue(w io.Writer, v interface{}, rows [][]string) error {
+	return c.outputTable(w, v, []string{"Field", "Value"}, rows)
+}
+
+// outputTable writes a value as JSON or YAML with --json or --format,
+// or the rows as a table with the header
+func (c *SMSCommand) outputTable(w io.Writer, v interface{}, header []string, rows [][]string) error {
+	format := c.Format
+	if format == "" && c.JSON {
+		format = "json"
+	}
+
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	case "yaml":
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(v); err != nil {
+			return err
+		}
+		return enc.Close()
+	case "", "table", "text":
+		table := tablewriter.NewTable(w, tablewriter.WithHeader(header))
+		for _, row := range rows {
+			table.Append(row)
+		}
+		return table.Render()
+	}
+	return fmt.Errorf("format %q is not supported for this command, use table, json or yaml", format)
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
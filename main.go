@@ -19,12 +19,436 @@ func main() {
 		os.Exit(0)
 	}
 
-	if os.Args[1] != "sms" {
+	switch os.Args[1] {
+	case "sms":
+		runSMS()
+	case "logout":
+		runLogout()
+	case "serve":
+		runServe()
+	case "smtp-gateway":
+		runSMTPGateway()
+	case "mqtt":
+		runMQTT()
+	case "exporter":
+		runExporter()
+	case "raw":
+		runRaw()
+	case "lte":
+		runLTE()
+	case "ussd":
+		runUSSD()
+	case "data":
+		runData()
+	case "reboot":
+		runReboot()
+	case "apn":
+		runAPN()
+	case "sim":
+		runSIM()
+	default:
 		fmt.Fprintf(os.Stderr, "unknown command: %s\n\n", os.Args[1])
 		PrintMainHelp()
 		os.Exit(1)
 	}
+}
+
+func runLogout() {
+	if len(os.Args) > 2 && (os.Args[2] == "-h" || os.Args[2] == "--help" || os.Args[2] == "help") {
+		PrintLogoutHelp()
+		os.Exit(0)
+	}
+
+	cmd, _, err := ParseArgs(os.Args[1:])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n\n", err)
+		PrintLogoutHelp()
+		os.Exit(1)
+	}
+
+	if err := cmd.Logout(context.Background()); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func runServe() {
+	if len(os.Args) > 2 && (os.Args[2] == "-h" || os.Args[2] == "--help" || os.Args[2] == "help") {
+		PrintServeHelp()
+		os.Exit(0)
+	}
+
+	cmd, _, err := ParseArgs(os.Args[1:])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n\n", err)
+		PrintServeHelp()
+		os.Exit(1)
+	}
+
+	if err := cmd.Serve(context.Background()); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func runSMTPGateway() {
+	if len(os.Args) > 2 && (os.Args[2] == "-h" || os.Args[2] == "--help" || os.Args[2] == "help") {
+		PrintSMTPGatewayHelp()
+		os.Exit(0)
+	}
+
+	cmd, _, err := ParseArgs(os.Args[1:])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n\n", err)
+		PrintSMTPGatewayHelp()
+		os.Exit(1)
+	}
+
+	if err := cmd.SMTPGateway(context.Background()); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func runMQTT() {
+	if len(os.Args) > 2 && (os.Args[2] == "-h" || os.Args[2] == "--help" || os.Args[2] == "help") {
+		PrintMQTTHelp()
+		os.Exit(0)
+	}
+
+	cmd, _, err := ParseArgs(os.Args[1:])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n\n", err)
+		PrintMQTTHelp()
+		os.Exit(1)
+	}
+
+	if err := cmd.MQTTBridge(context.Background()); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func runExporter() {
+	if len(os.Args) > 2 && (os.Args[2] == "-h" || os.Args[2] == "--help" || os.Args[2] == "help") {
+		PrintExporterHelp()
+		os.Exit(0)
+	}
+
+	cmd, _, err := ParseArgs(os.Args[1:])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n\n", err)
+		PrintExporterHelp()
+		os.Exit(1)
+	}
+
+	if err := cmd.Exporter(context.Background()); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func runRaw() {
+	if len(os.Args) > 2 && (os.Args[2] == "-h" || os.Args[2] == "--help" || os.Args[2] == "help") {
+		PrintRawHelp()
+		os.Exit(0)
+	}
+
+	cmd, _, err := ParseArgs(os.Args[1:])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n\n", err)
+		PrintRawHelp()
+		os.Exit(1)
+	}
+
+	if err := cmd.Raw(context.Background()); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func runLTE() {
+	if len(os.Args) < 3 {
+		PrintLTEHelp()
+		os.Exit(1)
+	}
+
+	if os.Args[2] == "-h" || os.Args[2] == "--help" || os.Args[2] == "help" {
+		PrintLTEHelp()
+		os.Exit(0)
+	}
+
+	cmd, subcommand, err := ParseArgs(os.Args[2:])
+	if err == nil {
+		formats := valueFormats
+		if subcommand == "monitor" {
+			// The monitor prints samples as lines of text or JSON
+			formats = []string{"table", "json"}
+		}
+		err = cmd.CheckFormat(formats...)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n\n", err)
+		PrintLTEHelp()
+		os.Exit(1)
+	}
+
+	ctx := context.Background()
+
+	switch subcommand {
+	case "status":
+		if err := cmd.LTEStatus(ctx); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+	case "monitor":
+		if err := cmd.LTEMonitor(ctx); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+	case "connect", "disconnect", "reconnect":
+		if err := cmd.LTEConnect(ctx, subcommand); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "unknown lte subcommand: %s\n\n", subcommand)
+		PrintLTEHelp()
+		os.Exit(1)
+	}
+}
+
+func runUSSD() {
+	if len(os.Args) < 3 {
+		PrintUSSDHelp()
+		os.Exit(1)
+	}
+
+	if os.Args[2] == "-h" || os.Args[2] == "--help" || os.Args[2] == "help" {
+		PrintUSSDHelp()
+		os.Exit(0)
+	}
+
+	cmd, subcommand, err := ParseArgs(os.Args[2:])
+	if err == nil {
+		err = cmd.CheckFormat("text", "json")
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n\n", err)
+		PrintUSSDHelp()
+		os.Exit(1)
+	}
+
+	ctx := context.Background()
+
+	switch subcommand {
+	case "send":
+		args := positionalArgs(os.Args[3:])
+		if len(args) == 0 {
+			fmt.Fprintf(os.Stderr, "error: send command requires a USSD code\n\n")
+			PrintUSSDHelp()
+			os.Exit(1)
+		}
+		if err := cmd.SendUSSD(ctx, args[0], args[1:]); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+	case "cancel":
+		if err := cmd.CancelUSSD(ctx); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "unknown ussd subcommand: %s\n\n", subcommand)
+		PrintUSSDHelp()
+		os.Exit(1)
+	}
+}
+
+func runSIM() {
+	if len(os.Args) < 3 {
+		PrintSIMHelp()
+		os.Exit(1)
+	}
+
+	if os.Args[2] == "-h" || os.Args[2] == "--help" || os.Args[2] == "help" {
+		PrintSIMHelp()
+		os.Exit(0)
+	}
+
+	cmd, subcommand, err := ParseArgs(os.Args[2:])
+	if err == nil {
+		err = cmd.CheckFormat(valueFormats...)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n\n", err)
+		PrintSIMHelp()
+		os.Exit(1)
+	}
+
+	ctx := context.Background()
+
+	switch subcommand {
+	case "status":
+		if err := cmd.SIMStatus(ctx); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+	case "unlock":
+		if err := cmd.SIMUnlock(ctx); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+	case "pin":
+		args := positionalArgs(os.Args[3:])
+		if len(args) == 0 {
+			fmt.Fprintf(os.Stderr, "error: pin command requires enable, disable or change\n\n")
+			PrintSIMHelp()
+			os.Exit(1)
+		}
+		if err := cmd.SIMPIN(ctx, args[0]); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "unknown sim subcommand: %s\n\n", subcommand)
+		PrintSIMHelp()
+		os.Exit(1)
+	}
+}
+
+func runAPN() {
+	if len(os.Args) < 3 {
+		PrintAPNHelp()
+		os.Exit(1)
+	}
+
+	if os.Args[2] == "-h" || os.Args[2] == "--help" || os.Args[2] == "help" {
+		PrintAPNHelp()
+		os.Exit(0)
+	}
+
+	cmd, subcommand, err := ParseArgs(os.Args[2:])
+	if err == nil {
+		err = cmd.CheckFormat(valueFormats...)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n\n", err)
+		PrintAPNHelp()
+		os.Exit(1)
+	}
+
+	ctx := context.Background()
+
+	switch subcommand {
+	case "list":
+		err = cmd.ListAPN(ctx)
+	case "add":
+		err = cmd.AddAPN(ctx)
+	case "edit", "delete", "activate":
+		args := positionalArgs(os.Args[3:])
+		if len(args) == 0 {
+			fmt.Fprintf(os.Stderr, "error: %s command requires a profile ID\n\n", subcommand)
+			PrintAPNHelp()
+			os.Exit(1)
+		}
+		id, convErr := strconv.Atoi(args[0])
+		if convErr != nil {
+			fmt.Fprintf(os.Stderr, "error: invalid profile ID: %s\n", args[0])
+			os.Exit(1)
+		}
+		switch subcommand {
+		case "edit":
+			err = cmd.EditAPN(ctx, id)
+		case "delete":
+			err = cmd.DeleteAPN(ctx, id)
+		case "activate":
+			err = cmd.ActivateAPN(ctx, id)
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "unknown apn subcommand: %s\n\n", subcommand)
+		PrintAPNHelp()
+		os.Exit(1)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func runReboot() {
+	if len(os.Args) > 2 && (os.Args[2] == "-h" || os.Args[2] == "--help" || os.Args[2] == "help") {
+		PrintRebootHelp()
+		os.Exit(0)
+	}
+
+	cmd, _, err := ParseArgs(os.Args[1:])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n\n", err)
+		PrintRebootHelp()
+		os.Exit(1)
+	}
+
+	if err := cmd.Reboot(context.Background()); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(rebootExitCode(err))
+	}
+}
+
+func runData() {
+	if len(os.Args) < 3 {
+		PrintDataHelp()
+		os.Exit(1)
+	}
+
+	if os.Args[2] == "-h" || os.Args[2] == "--help" || os.Args[2] == "help" {
+		PrintDataHelp()
+		os.Exit(0)
+	}
+
+	cmd, subcommand, err := ParseArgs(os.Args[2:])
+	if err == nil {
+		err = cmd.CheckFormat(valueFormats...)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n\n", err)
+		PrintDataHelp()
+		os.Exit(1)
+	}
+
+	ctx := context.Background()
+
+	switch subcommand {
+	case "usage":
+		if err := cmd.DataUsage(ctx); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+	case "reset":
+		if err := cmd.DataReset(ctx); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+	case "set-limit":
+		args := positionalArgs(os.Args[3:])
+		if len(args) == 0 {
+			fmt.Fprintf(os.Stderr, "error: set-limit command requires a size or off\n\n")
+			PrintDataHelp()
+			os.Exit(1)
+		}
+		if err := cmd.DataSetLimit(ctx, args[0]); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "unknown data subcommand: %s\n\n", subcommand)
+		PrintDataHelp()
+		os.Exit(1)
+	}
+}
 
+func runSMS() {
 	if len(os.Args) < 3 {
 		PrintSMSHelp()
 		os.Exit(1)
@@ -105,19 +529,64 @@ func main() {
 			PrintSendHelp()
 			os.Exit(0)
 		}
-		if len(os.Args) < 5 {
-			fmt.Fprintf(os.Stderr, "error: send command requires a phone number and message\n\n")
-			PrintSendHelp()
+		if len(os.Args) < 5 {
+			fmt.Fprintf(os.Stderr, "error: send command requires a phone number and message\n\n")
+			PrintSendHelp()
+			os.Exit(1)
+		}
+		number := os.Args[3]
+		message := os.Args[4]
+		if message == "" {
+			fmt.Fprintf(os.Stderr, "error: message cannot be empty\n\n")
+			PrintSendHelp()
+			os.Exit(1)
+		}
+		if err := cmd.SendSMS(ctx, number, message); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+	case "backup":
+		if len(os.Args) > 3 && (os.Args[3] == "-h" || os.Args[3] == "--help" || os.Args[3] == "help") {
+			PrintBackupHelp()
+			os.Exit(0)
+		}
+		if err := cmd.BackupSMS(ctx); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+	case "watch":
+		if len(os.Args) > 3 && (os.Args[3] == "-h" || os.Args[3] == "--help" || os.Args[3] == "help") {
+			PrintWatchHelp()
+			os.Exit(0)
+		}
+		if err := cmd.WatchSMS(ctx); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+	case "webhook":
+		if len(os.Args) > 3 && (os.Args[3] == "-h" || os.Args[3] == "--help" || os.Args[3] == "help") {
+			PrintWebhookHelp()
+			os.Exit(0)
+		}
+		if err := cmd.WebhookSMS(ctx); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+	case "forward-email":
+		if len(os.Args) > 3 && (os.Args[3] == "-h" || os.Args[3] == "--help" || os.Args[3] == "help") {
+			PrintForwardEmailHelp()
+			os.Exit(0)
+		}
+		if err := cmd.ForwardEmail(ctx); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
 			os.Exit(1)
 		}
-		number := os.Args[3]
-		message := os.Args[4]
-		if message == "" {
-			fmt.Fprintf(os.Stderr, "error: message cannot be empty\n\n")
-			PrintSendHelp()
-			os.Exit(1)
+	case "autorespond":
+		if len(os.Args) > 3 && (os.Args[3] == "-h" || os.Args[3] == "--help" || os.Args[3] == "help") {
+			PrintAutoRespondHelp()
+			os.Exit(0)
 		}
-		if err := cmd.SendSMS(ctx, number, message); err != nil {
+		if err := cmd.AutoRespond(ctx); err != nil {
 			fmt.Fprintf(os.Stderr, "error: %v\n", err)
 			os.Exit(1)
 		}
@@ -136,12 +605,36 @@ Usage:
 
 Commands:
   sms                 Manage SMS messages
+  logout              End the router web session
+  serve               Run the SMS gateway REST API
+  smtp-gateway        Send mail to <number>@sms.local as SMS
+  mqtt                Bridge SMS to and from an MQTT broker
+  exporter            Serve Prometheus metrics for the router
+  raw                 Send a request to any router controller
+  lte                 Show the LTE connection and signal
+  ussd                Send USSD codes, such as a balance query
+  data                Show and reset mobile data usage and limit
+  reboot              Restart the router
+  apn                 Manage APN profiles
+  sim                 Show the SIM status and manage the PIN
   help, -h, --help    Show this help message
 
 Examples:
   tp-link-cli sms list
   tp-link-cli sms read 1
   tp-link-cli sms delete 1
+  tp-link-cli logout
+  tp-link-cli serve --listen=:8080 --token=secret
+  tp-link-cli smtp-gateway --listen=:2525 --allow-from=@example.com
+  tp-link-cli mqtt --broker=localhost:1883
+  tp-link-cli exporter --listen=:9110
+  tp-link-cli raw --method=gl --controller=LAN_HOST_ENTRY --attrs=IPAddress,MACAddress
+  tp-link-cli lte status
+  tp-link-cli ussd send "*123#"
+  tp-link-cli data usage
+  tp-link-cli reboot --wait
+  tp-link-cli apn list
+  tp-link-cli sim status
   tp-link-cli help
 
 `)
@@ -154,11 +647,16 @@ Usage:
   tp-link-cli sms <command> [options]
 
 Commands:
-  list          List all SMS messages
+  list          List SMS messages (one page, or --all)
   read <id>     Read a specific message by ID
   delete <pos>  Delete a message by position (1-based)
   delete-id <id>    Delete a message by ID
   send <number> <message>  Send an SMS message
+  backup --out=<file>  Back up all messages to a JSON lines archive
+  watch         Report new inbox messages as they arrive
+  webhook --url=<url>  Post new inbox messages to a webhook
+  forward-email --smtp=<addr> --mail-to=<list>  Relay new inbox messages as email
+  autorespond --rules=<file>  Reply to, forward or delete messages by rules
   help, -h, --help  Show this help message
 
 Global Options:
@@ -166,16 +664,33 @@ Global Options:
   --host=<ip>          Router IP address (default: 192.168.1.1)
   --folder=<folder>    Message folder: inbox or sent (default: inbox)
   --json               Output results as JSON
+  --format=<format>    Output format: table, text, json, csv, tsv, yaml,
+                       markdown, mbox or template (default: table, text for read)
+  --template=<tpl>     Go text/template, executed for each message
+  --session-cache      Reuse the router session between invocations
+  --timezone=<zone>    Time zone of the router clock, e.g. Europe/Ljubljana
+                       (default: local time zone)
+  --page=<n>           Page to list, 8 messages per page (default: 1)
+  --all                List messages from all pages
 
 Examples:
   tp-link-cli sms list
   tp-link-cli sms list --folder=sent
   tp-link-cli sms list --json
+  tp-link-cli sms list --page=2
+  tp-link-cli sms list --all --folder=sent
+  tp-link-cli sms list --all --format=mbox > inbox.mbox
+  tp-link-cli sms list --template='{{.From}}: {{.Content}}'
   tp-link-cli sms read 5
   tp-link-cli sms read 5 --folder=sent
   tp-link-cli sms delete 1
   tp-link-cli sms delete-id 12345
   tp-link-cli sms send 0038612345678 "Hello, this is a test message"
+  tp-link-cli sms backup --out=archive.jsonl --incremental
+  tp-link-cli sms watch --interval=10s --state=watch.json
+  tp-link-cli sms webhook --url=https://example.com/sms --state=webhook.json
+  tp-link-cli sms forward-email --smtp=mail.example.com:25 --mail-to=ops@example.com
+  tp-link-cli sms autorespond --rules=rules.yaml
   tp-link-cli sms list --host=192.168.1.100 --auth=admin:mypassword
 
 `)
@@ -195,11 +710,15 @@ Options:
   --host=<ip>          Router IP address (default: 192.168.1.1)
   --folder=<folder>    Message folder: inbox or sent (default: inbox)
   --json               Output result as JSON
+  --format=<format>    Output format: text, table, json, csv, tsv, yaml,
+                       markdown, mbox or template (default: text)
+  --template=<tpl>     Go text/template, executed for the message
 
 Examples:
   tp-link-cli sms read 1
   tp-link-cli sms read 5 --folder=sent
   tp-link-cli sms read 3 --json
+  tp-link-cli sms read 3 --format=yaml
 
 `)
 }
@@ -271,3 +790,580 @@ Examples:
 
 `)
 }
+
+func PrintBackupHelp() {
+	fmt.Fprintf(os.Stdout, `SMS Backup Command
+
+Usage:
+  tp-link-cli sms backup --out=<file> [options]
+
+Walks every page of the inbox and sent folders and writes the messages
+to a JSON lines archive, one message per line. Each record carries the
+folder, router host and a content hash. Records are deduplicated by
+host and hash and sorted oldest first, so archives from multiple
+routers can be merged safely. An existing archive is merged with the
+messages on the router, keeping messages deleted from it since.
+
+Options:
+  --auth=<user:pass>   Authentication credentials (default: admin:admin)
+  --host=<ip>          Router IP address (default: 192.168.1.1)
+  --out=<file>         Archive file to write or merge into (required)
+  --incremental        Only append messages not already in the archive
+
+Examples:
+  tp-link-cli sms backup --out=archive.jsonl
+  tp-link-cli sms backup --out=archive.jsonl --incremental
+
+`)
+}
+
+func PrintWatchHelp() {
+	fmt.Fprintf(os.Stdout, `SMS Watch Command
+
+Usage:
+  tp-link-cli sms watch [options]
+
+Polls the inbox over a single router session and reports each new
+message, oldest first, as one JSON line. With --exec, the command is
+run through "sh -c" for each message instead, with the message in the
+SMS_INDEX, SMS_FROM, SMS_TO, SMS_CONTENT, SMS_TIME, SMS_UNREAD,
+SMS_FOLDER, SMS_HOST and SMS_HASH environment variables.
+
+Messages already in the inbox on the first start are marked as seen,
+not reported, unless --all is given. With --state, the seen messages
+are kept in a file, so a restarted watch does not report them again.
+
+Options:
+  --auth=<user:pass>   Authentication credentials (default: admin:admin)
+  --host=<ip>          Router IP address (default: 192.168.1.1)
+  --interval=<dur>     Poll interval, e.g. 10s or 1m (default: 30s)
+  --state=<file>       State file for the seen messages
+  --exec=<command>     Command to run for each new message
+  --all                Also report messages already in the inbox
+
+Examples:
+  tp-link-cli sms watch
+  tp-link-cli sms watch --interval=10s --state=watch.json
+  tp-link-cli sms watch --state=watch.json --exec='echo "$SMS_FROM: $SMS_CONTENT"'
+
+`)
+}
+
+func PrintWebhookHelp() {
+	fmt.Fprintf(os.Stdout, `SMS Webhook Command
+
+Usage:
+  tp-link-cli sms webhook --url=<url> [options]
+
+Polls the inbox like 'sms watch', and POSTs each new message to the
+URL as JSON. With a secret, the body is signed with HMAC-SHA256 in
+the X-Signature-256 header, as "sha256=<hex>".
+
+Failed deliveries are retried 5 times with exponential backoff, on
+network errors, 429 and 5xx responses. Messages that could not be
+delivered are appended to the dead-letter file as JSON lines.
+
+Options:
+  --auth=<user:pass>   Authentication credentials (default: admin:admin)
+  --host=<ip>          Router IP address (default: 192.168.1.1)
+  --url=<url>          Webhook URL (required)
+  --secret=<secret>    HMAC secret (or TP_LINK_CLI_WEBHOOK_SECRET)
+  --dead-letter=<file> File for undeliverable messages
+  --interval=<dur>     Poll interval, e.g. 10s or 1m (default: 30s)
+  --state=<file>       State file for the seen messages
+  --all                Also post messages already in the inbox
+
+Examples:
+  tp-link-cli sms webhook --url=https://example.com/sms --state=webhook.json
+  tp-link-cli sms webhook --url=http://localhost:9000/hook --secret=s3cret --dead-letter=failed.jsonl
+
+`)
+}
+
+func PrintForwardEmailHelp() {
+	fmt.Fprintf(os.Stdout, `SMS Forward Email Command
+
+Usage:
+  tp-link-cli sms forward-email --smtp=<host:port> --mail-to=<list> [options]
+
+Polls the inbox like 'sms watch', and relays each new message to the
+SMTP server as an individual email. The From header carries the sender
+number as <number>@sms.local, and the Date header the time the message
+was received. STARTTLS is used when the server offers it.
+
+With --delete, a message is deleted from the router once the server
+accepted it. Messages the server rejects are kept in the inbox.
+
+Options:
+  --auth=<user:pass>       Authentication credentials (default: admin:admin)
+  --host=<ip>              Router IP address (default: 192.168.1.1)
+  --smtp=<host:port>       SMTP relay to deliver to (required)
+  --smtp-auth=<user:pass>  SMTP PLAIN credentials (or TP_LINK_CLI_SMTP_AUTH)
+  --mail-to=<list>         Comma separated recipient addresses (required)
+  --mail-from=<address>    Envelope sender (default: <number>@sms.local)
+  --delete                 Delete messages from the router once delivered
+  --interval=<dur>         Poll interval, e.g. 10s or 1m (default: 30s)
+  --state=<file>           State file for the seen messages
+  --all                    Also forward messages already in the inbox
+
+Examples:
+  tp-link-cli sms forward-email --smtp=mail.example.com:25 --mail-to=ops@example.com
+  tp-link-cli sms forward-email --smtp=smtp.example.com:587 --smtp-auth=router:s3cret \
+    --mail-from=router@example.com --mail-to=ops@example.com --all --delete
+
+`)
+}
+
+func PrintAutoRespondHelp() {
+	fmt.Fprintf(os.Stdout, `SMS Autorespond Command
+
+Usage:
+  tp-link-cli sms autorespond --rules=<file> [options]
+
+Applies YAML rules to the inbox, oldest message first. A rule matches
+on the sender, a regular expression on the content and the message
+age, and runs its actions: reply to the sender, forward to a number,
+or delete the message.
+
+Replies and forwards are checked against the sent folder, and skipped
+if the same text was already sent to the number after the message was
+received, so each message is answered exactly once.
+
+Rules file:
+  rules:
+    - name: brzina
+      from: "13909"          # sender, exact match
+      match: "BRZINA"        # regular expression on the content
+      max_age: 24h           # skip older messages
+      actions:
+        - reply: brzina
+        - forward: "0038612345678"
+          content: "Quota router reset after 200G limit."
+        - delete: true
+
+Options:
+  --auth=<user:pass>   Authentication credentials (default: admin:admin)
+  --host=<ip>          Router IP address (default: 192.168.1.1)
+  --rules=<file>       Rules file (required)
+  --dry-run            Print the actions without sending or deleting
+  --interval=<dur>     Apply the rules repeatedly, e.g. 1m (default: once)
+
+Examples:
+  tp-link-cli sms autorespond --rules=rules.yaml
+  tp-link-cli sms autorespond --rules=rules.yaml --dry-run
+  tp-link-cli sms autorespond --rules=rules.yaml --interval=1m
+
+`)
+}
+
+func PrintLogoutHelp() {
+	fmt.Fprintf(os.Stdout, `Logout Command
+
+Usage:
+  tp-link-cli logout [options]
+
+The router allows a single admin web session. SMS commands log out when
+done, unless --session-cache is used. This command ends the session,
+including a cached one, so the web UI can be used again.
+
+Options:
+  --auth=<user:pass>   Authentication credentials (default: admin:admin)
+  --host=<ip>          Router IP address (default: 192.168.1.1)
+
+Examples:
+  tp-link-cli logout
+  tp-link-cli logout --host=192.168.1.100 --auth=admin:mypassword
+
+`)
+}
+
+func PrintServeHelp() {
+	fmt.Fprintf(os.Stdout, `Serve Command
+
+Usage:
+  tp-link-cli serve [options]
+
+Runs an SMS gateway REST API backed by a single router session.
+Requests are handled one at a time, as the router session is not
+safe for concurrent use. Every request must carry the token in an
+"Authorization: Bearer <token>" header.
+
+Endpoints:
+  GET    /sms/{folder}        List messages (?page=<n>, or ?all=true)
+  GET    /sms/{folder}/{id}   Read a message by ID
+  DELETE /sms/{folder}/{id}   Delete a message by ID
+  POST   /sms                 Send a message: {"to": "...", "content": "..."}
+
+Options:
+  --auth=<user:pass>   Authentication credentials (default: admin:admin)
+  --host=<ip>          Router IP address (default: 192.168.1.1)
+  --listen=<addr>      Address to listen on (default: :8080)
+  --token=<token>      Bearer token required by clients (or TP_LINK_CLI_TOKEN)
+  --session-cache      Keep the router session on shutdown
+
+Examples:
+  tp-link-cli serve --token=secret
+  TP_LINK_CLI_TOKEN=secret tp-link-cli serve --listen=127.0.0.1:8080
+  curl -H "Authorization: Bearer secret" http://localhost:8080/sms/inbox
+
+`)
+}
+
+func PrintSMTPGatewayHelp() {
+	fmt.Fprintf(os.Stdout, `SMTP Gateway Command
+
+Usage:
+  tp-link-cli smtp-gateway [options]
+
+Runs an SMTP server which accepts mail addressed to <number>@sms.local
+and sends the subject and plain text body as an SMS to the number, over
+a single router session. The server has no TLS or authentication, so
+it listens on 127.0.0.1 by default, and other addresses require
+--allow-from. Only expose it to trusted hosts.
+
+Options:
+  --auth=<user:pass>       Authentication credentials (default: admin:admin)
+  --host=<ip>              Router IP address (default: 192.168.1.1)
+  --listen=<addr>          Address to listen on (default: 127.0.0.1:2525)
+  --allow-from=<list>      Comma separated envelope senders to accept, as
+                           addresses or @domain (default: any sender,
+                           only allowed on a loopback address)
+  --rate-limit=<n>/<dur>   Messages allowed per recipient number within
+                           the duration, e.g. 10/1h (default: no limit)
+  --session-cache          Keep the router session on shutdown
+
+Examples:
+  tp-link-cli smtp-gateway --rate-limit=10/1h
+  tp-link-cli smtp-gateway --listen=:2525 --allow-from=nagios@example.com,@monitoring.local
+
+`)
+}
+
+func PrintMQTTHelp() {
+	fmt.Fprintf(os.Stdout, `MQTT Command
+
+Usage:
+  tp-link-cli mqtt --broker=<host:port> [options]
+
+Bridges the router SMS to an MQTT broker over a single router session,
+with requests to the router handled one at a time. Topics are below
+tplink/<host> by default:
+
+  <topic>/sms/inbox        New inbox messages are published as JSON
+  <topic>/sms/send         Send requests: {"id": "...", "to": "...", "text": "..."}
+  <topic>/sms/send/result  Results: {"id": "...", "to": "...", "text": "...",
+                           "ok": true, "error": "..."}
+
+The id is optional, and returned in the result for correlation. The
+inbox is polled like 'sms watch'. A lost broker connection is retried
+with backoff.
+
+Options:
+  --auth=<user:pass>       Authentication credentials (default: admin:admin)
+  --host=<ip>              Router IP address (default: 192.168.1.1)
+  --broker=<addr>          Broker as host:port, tcp://host:port or
+                           ssl://host:port (required)
+  --mqtt-auth=<user:pass>  Broker credentials (or TP_LINK_CLI_MQTT_AUTH)
+  --topic=<prefix>         Topic prefix (default: tplink/<host>)
+  --interval=<dur>         Poll interval, e.g. 10s or 1m (default: 30s)
+  --state=<file>           State file for the seen messages
+  --all                    Also publish messages already in the inbox
+  --session-cache          Keep the router session on shutdown
+
+Examples:
+  tp-link-cli mqtt --broker=localhost:1883
+  tp-link-cli mqtt --broker=ssl://broker.example.com --mqtt-auth=ha:s3cret --state=mqtt.json
+  mosquitto_pub -t tplink/192.168.1.1/sms/send -m '{"to":"13909","text":"BRZINA"}'
+
+`)
+}
+
+func PrintExporterHelp() {
+	fmt.Fprintf(os.Stdout, `Exporter Command
+
+Usage:
+  tp-link-cli exporter [options]
+
+Serves Prometheus metrics on /metrics. Every scrape reads the SMS
+folder totals and the LTE signal from the router, over a single
+router session, one scrape at a time.
+
+Metrics:
+  tplink_up                                Whether the router scrape succeeded
+  tplink_sms_messages{folder}              Messages in the inbox and sent folders
+  tplink_sms_unread_messages               Unread messages in the inbox
+  tplink_lte_signal_level                  Signal strength in bars, if reported
+  tplink_lte_rsrp_dbm, tplink_lte_rsrq_db, tplink_lte_snr_db, tplink_lte_rssi_dbm
+  tplink_router_request_duration_seconds   Request latency by controller
+  tplink_router_request_failures_total     Failed requests by controller
+  tplink_router_errors_total{code}         Router error codes by controller
+  tplink_router_logins_total, tplink_router_login_failures_total
+
+Options:
+  --auth=<user:pass>   Authentication credentials (default: admin:admin)
+  --host=<ip>          Router IP address (default: 192.168.1.1)
+  --listen=<addr>      Address to listen on (default: :9110)
+  --session-cache      Keep the router session on shutdown
+
+Examples:
+  tp-link-cli exporter
+  tp-link-cli exporter --listen=127.0.0.1:9110
+  curl http://localhost:9110/metrics
+
+`)
+}
+
+func PrintRawHelp() {
+	fmt.Fprintf(os.Stdout, `Raw Command
+
+Usage:
+  tp-link-cli raw --controller=<name> [options]
+
+Sends a single request to a controller of the router data model, and
+prints the decoded response as JSON, with the router error code and
+the returned objects. Use it to explore the router API.
+
+Attributes are names to read, e.g. --attrs=IPAddress,MACAddress. If
+any attribute has a value, e.g. --attrs=enable=1, the attributes are
+sent as name=value pairs, as a set expects.
+
+Options:
+  --auth=<user:pass>    Authentication credentials (default: admin:admin)
+  --host=<ip>           Router IP address (default: 192.168.1.1)
+  --method=<method>     get, set, add, del, gl (get list), gs (get set) or cgi
+                        (default: get)
+  --controller=<name>   Controller name, e.g. LTE_NET_STATUS (required)
+  --attrs=<list>        Comma separated attribute names or name=value pairs
+  --stack=<stack>       Object stack (default: 0,0,0,0,0,0)
+
+Examples:
+  tp-link-cli raw --method=gl --controller=LAN_HOST_ENTRY --attrs=IPAddress,MACAddress
+  tp-link-cli raw --controller=LTE_NET_STATUS --stack=2,1,0,0,0,0
+  tp-link-cli raw --method=get --controller=DEV_INFO --attrs=modelName,softwareVersion
+
+`)
+}
+
+func PrintLTEHelp() {
+	fmt.Fprintf(os.Stdout, `LTE Commands
+
+Usage:
+  tp-link-cli lte <command> [options]
+
+Commands:
+  status        Show the connection state, operator and signal
+  monitor       Sample the signal until interrupted, then summarize it
+  connect       Enable the LTE data connection
+  disconnect    Disable the LTE data connection
+  reconnect     Disconnect and connect again, for a new data session
+  help, -h, --help  Show this help message
+
+The status reads the WAN_LTE_LINK_CFG, LTE_NET_STATUS and LTE_PROF_STAT
+controllers: connection state, network type, operator, roaming and SIM
+state, signal bars, RSRP, RSRQ, SINR, RSSI, band, channel and cell ID.
+Values the router does not report are left empty.
+
+The monitor reads the status every --interval over one router session,
+printing a line per sample. On Ctrl+C it prints the min/avg/max RSRP,
+RSRQ, SINR and RSSI of the connected samples, the connected share and
+the number of outages. With --out, samples are appended to a history
+file, CSV if it ends in .csv and JSON lines otherwise, keeping the last
+--keep samples.
+
+Connect, disconnect and reconnect set the enable flag of WAN_LTE_LINK_CFG
+and poll the status every --interval until the connection settles, for
+up to --timeout. The public IP of the new connection is printed.
+
+Options:
+  --auth=<user:pass>   Authentication credentials (default: admin:admin)
+  --host=<ip>          Router IP address (default: 192.168.1.1)
+  --json               Output result as JSON
+  --format=<format>    Output format: table, json or yaml (default: table),
+                       the monitor prints table or json
+  --interval=<dur>     Monitor sampling interval (default: 10s), or the
+                       connect poll interval (default: 1s)
+  --timeout=<dur>      Time to wait for a connection change (default: 1m)
+  --out=<file>         Monitor history file, .csv or JSON lines
+  --keep=<n>           Samples kept in the history file (default: 8640)
+
+Examples:
+  tp-link-cli lte status
+  tp-link-cli lte status --json
+  tp-link-cli lte monitor --interval=10s --out=signal.csv
+  tp-link-cli lte reconnect
+
+`)
+}
+
+func PrintUSSDHelp() {
+	fmt.Fprintf(os.Stdout, `USSD Commands
+
+Usage:
+  tp-link-cli ussd send <code> [choice...] [options]
+  tp-link-cli ussd cancel [options]
+
+Commands:
+  send          Send a USSD code and print the reply
+  cancel        End an open USSD session
+  help, -h, --help  Show this help message
+
+The code is sent through the LTE_USSD controller, and the status is
+polled until the network replies, for up to a minute. A reply with a
+menu keeps the session open: the choices given after the code are sent
+in turn, and further choices are read from the terminal, where an empty
+line ends the session. Without a terminal, or on Ctrl+C, an open session
+is cancelled.
+
+Options:
+  --auth=<user:pass>   Authentication credentials (default: admin:admin)
+  --host=<ip>          Router IP address (default: 192.168.1.1)
+  --json               Output each reply as a JSON line
+
+Examples:
+  tp-link-cli ussd send "*123#"
+  tp-link-cli ussd send "*123#" 2 1
+  tp-link-cli ussd cancel
+
+`)
+}
+
+func PrintDataHelp() {
+	fmt.Fprintf(os.Stdout, `Data Usage Commands
+
+Usage:
+  tp-link-cli data <command> [options]
+
+Commands:
+  usage               Show the data statistics and limit
+  reset               Clear the statistics, starting a new period
+  set-limit <size>    Set the data limit, or disable it with off
+  help, -h, --help    Show this help message
+
+The statistics and limit are read from the WAN_LTE_INTF_CFG controller:
+the usage in the billing period, total, received and sent data, current
+rates, the limit, the alert threshold and the billing day. Sizes take a
+K, M, G or T suffix in powers of 1024, as on the router.
+
+Options:
+  --auth=<user:pass>   Authentication credentials (default: admin:admin)
+  --host=<ip>          Router IP address (default: 192.168.1.1)
+  --json               Output result as JSON, sizes in bytes
+  --format=<format>    Output format: table, json or yaml (default: table)
+  --alert=<percent>    set-limit: alert at this share of the limit
+  --billing-day=<day>  set-limit: day the period starts, 0 to reset by hand
+
+Examples:
+  tp-link-cli data usage
+  tp-link-cli data usage --json
+  tp-link-cli data set-limit 200G --alert=90 --billing-day=1
+  tp-link-cli data reset
+
+`)
+}
+
+func PrintRebootHelp() {
+	fmt.Fprintf(os.Stdout, `Reboot Command
+
+Usage:
+  tp-link-cli reboot [options]
+
+Restarts the router. With --wait, the command waits for the router to
+stop answering, then polls /cgi/getParm until it answers again and a
+login succeeds, so the router is usable when the command returns. The
+router may drop the connection as it restarts, so with --wait only an
+error code from the router fails the reboot request.
+
+Exit codes:
+  0   Rebooted, and back up with --wait
+  1   The login or reboot request failed
+  2   The router did not go down within the timeout
+  3   The router did not come back within the timeout
+
+Options:
+  --auth=<user:pass>   Authentication credentials (default: admin:admin)
+  --host=<ip>          Router IP address (default: 192.168.1.1)
+  --wait               Wait for the router to come back
+  --timeout=<dur>      Time to wait for the router (default: 5m)
+  --interval=<dur>     Interval between polls (default: 2s)
+
+Examples:
+  tp-link-cli reboot
+  tp-link-cli reboot --wait --timeout=3m
+
+`)
+}
+
+func PrintAPNHelp() {
+	fmt.Fprintf(os.Stdout, `APN Profile Commands
+
+Usage:
+  tp-link-cli apn <command> [options]
+
+Commands:
+  list              List the profiles, marking the active one
+  add               Add a profile
+  edit <id>         Change the given options of a profile
+  delete <id>       Delete a profile
+  activate <id>     Use a profile for the data connection
+  help, -h, --help  Show this help message
+
+Profiles are validated before they are sent to the router: the name is
+required, the APN must be a valid access point name, and pap or chap
+authentication requires a username. Built-in profiles can't be edited
+or deleted, and the active profile can't be deleted.
+
+Options:
+  --auth=<user:pass>   Authentication credentials (default: admin:admin)
+  --host=<ip>          Router IP address (default: 192.168.1.1)
+  --json               Output result as JSON
+  --format=<format>    Output format: table, json or yaml (default: table)
+  --name=<name>        Profile name
+  --apn=<apn>          Access point name
+  --auth-type=<type>   none, pap or chap (default: none)
+  --username=<user>    APN username
+  --password           Read the APN password from stdin, if
+                       TP_LINK_CLI_APN_PASSWORD is not set, prompting
+                       without echo on a terminal
+  --pdp-type=<type>    ipv4, ipv6 or ipv4v6 (default: ipv4)
+
+Examples:
+  tp-link-cli apn list
+  tp-link-cli apn add --name=Work --apn=corp.example --auth-type=pap --username=user --password
+  tp-link-cli apn activate 3
+
+`)
+}
+
+func PrintSIMHelp() {
+	fmt.Fprintf(os.Stdout, `SIM Commands
+
+Usage:
+  tp-link-cli sim <command> [options]
+
+Commands:
+  status                 Show the SIM state, IMSI, ICCID and PIN attempts
+  unlock [--pin]         Enter the PIN of a locked SIM
+  pin enable|disable     Turn the PIN lock on or off
+  pin change             Change the PIN, the PIN lock must be enabled
+  help, -h, --help       Show this help message
+
+PINs are never taken from the command line, where other users can see
+them. The PIN is read from TP_LINK_CLI_SIM_PIN, or the first line of
+stdin, and the new PIN for pin change from TP_LINK_CLI_SIM_NEW_PIN, or
+the next line. On a terminal, the PINs are prompted for without echo.
+A rejected PIN reports the attempts left, once they run out the SIM
+needs the PUK.
+
+Options:
+  --auth=<user:pass>   Authentication credentials (default: admin:admin)
+  --host=<ip>          Router IP address (default: 192.168.1.1)
+  --json               Output result as JSON
+  --format=<format>    Output format: table, json or yaml (default: table)
+
+Examples:
+  tp-link-cli sim status
+  TP_LINK_CLI_SIM_PIN=1234 tp-link-cli sim unlock
+  printf '1234\n5678\n' | tp-link-cli sim pin change
+
+`)
+}
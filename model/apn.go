@@ -0,0 +1,23 @@
+package model
+
+// APNProfile is a mobile data profile of the router.
+type APNProfile struct {
+	// ID identifies the profile, it is assigned by the router.
+	ID   int    `json:"id" yaml:"id"`
+	Name string `json:"name" yaml:"name"`
+	APN  string `json:"apn" yaml:"apn"`
+
+	// AuthType is none, pap or chap.
+	AuthType string `json:"authType" yaml:"authType"`
+	Username string `json:"username,omitempty" yaml:"username,omitempty"`
+	// Password sets a new password. It is not read from the router,
+	// which may mask it, and not encoded, so it isn't printed.
+	Password string `json:"-" yaml:"-"`
+
+	// PDPType is ipv4, ipv6 or ipv4v6.
+	PDPType string `json:"pdpType" yaml:"pdpType"`
+
+	// Default is set for the built-in profiles, which can't be changed.
+	Default bool `json:"default" yaml:"default"`
+	Active  bool `json:"active" yaml:"active"`
+}
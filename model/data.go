@@ -0,0 +1,38 @@
+package model
+
+// DataLimit is the mobile data limit of the router. Sizes are in bytes.
+type DataLimit struct {
+	Enabled bool   `json:"enabled" yaml:"enabled"`
+	Limit   uint64 `json:"limit" yaml:"limit"`
+
+	// AlertPercent is the share of the limit which triggers an alert.
+	AlertPercent int `json:"alertPercent" yaml:"alertPercent"`
+
+	// BillingDay is the day of the month the billing period starts, or
+	// zero if the period usage is only reset by hand.
+	BillingDay int `json:"billingDay" yaml:"billingDay"`
+}
+
+// DataUsage holds the mobile data statistics and limit. Sizes are in
+// bytes, rates in bytes per second.
+type DataUsage struct {
+	// Period is the usage in the current billing period.
+	Period uint64 `json:"period" yaml:"period"`
+	Total  uint64 `json:"total" yaml:"total"`
+	RX     uint64 `json:"rx" yaml:"rx"`
+	TX     uint64 `json:"tx" yaml:"tx"`
+
+	RXRate uint64 `json:"rxRate" yaml:"rxRate"`
+	TXRate uint64 `json:"txRate" yaml:"txRate"`
+
+	DataLimit `yaml:",inline"`
+}
+
+// Remaining returns the data left in the billing period, or zero if the
+// limit is disabled or used up.
+func (u DataUsage) Remaining() uint64 {
+	if !u.Enabled || u.Period >= u.Limit {
+		return 0
+	}
+	return u.Limit - u.Period
+}
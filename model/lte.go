@@ -0,0 +1,42 @@
+package model
+
+import "time"
+
+// Signal holds the LTE signal quality reported by the router.
+type Signal struct {
+	// Level is the signal strength in bars, 0 to 5.
+	Level int     `json:"level" yaml:"level"`
+	RSRP  float64 `json:"rsrp" yaml:"rsrp"`
+	RSRQ  float64 `json:"rsrq" yaml:"rsrq"`
+	SNR   float64 `json:"snr" yaml:"snr"`
+	RSSI  float64 `json:"rssi" yaml:"rssi"`
+
+	Band    string `json:"band,omitempty" yaml:"band,omitempty"`
+	Channel string `json:"channel,omitempty" yaml:"channel,omitempty"`
+	CellID  string `json:"cellId,omitempty" yaml:"cellId,omitempty"`
+}
+
+// LTEStatus holds the LTE connection state, operator and signal.
+type LTEStatus struct {
+	Signal `yaml:",inline"`
+
+	Operator    string `json:"operator,omitempty" yaml:"operator,omitempty"`
+	NetworkType string `json:"networkType" yaml:"networkType"`
+	Connection  string `json:"connection" yaml:"connection"`
+	Connected   bool   `json:"connected" yaml:"connected"`
+	Enabled     bool   `json:"enabled" yaml:"enabled"`
+	Roaming     bool   `json:"roaming" yaml:"roaming"`
+	SIMStatus   string `json:"simStatus" yaml:"simStatus"`
+
+	// IP is the public IPv4 address of the connection, if connected.
+	IP string `json:"ip,omitempty" yaml:"ip,omitempty"`
+}
+
+// SignalSample is the LTE status at a point in time, as recorded by a
+// signal monitor. Error is set if the router could not be read.
+type SignalSample struct {
+	Time  time.Time `json:"time" yaml:"time"`
+	Error string    `json:"error,omitempty" yaml:"error,omitempty"`
+
+	LTEStatus `yaml:",inline"`
+}
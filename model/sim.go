@@ -0,0 +1,14 @@
+package model
+
+// SIMInfo holds the SIM state and PIN lock of the router.
+type SIMInfo struct {
+	// State is the SIM state, as in LTEStatus.SIMStatus.
+	State string `json:"state" yaml:"state"`
+	IMSI  string `json:"imsi,omitempty" yaml:"imsi,omitempty"`
+	ICCID string `json:"iccid,omitempty" yaml:"iccid,omitempty"`
+
+	PINEnabled  bool `json:"pinEnabled" yaml:"pinEnabled"`
+	PINAttempts int  `json:"pinAttempts" yaml:"pinAttempts"`
+	PUKAttempts int  `json:"pukAttempts" yaml:"pukAttempts"`
+	PUKRequired bool `json:"pukRequired" yaml:"pukRequired"`
+}
@@ -1,16 +1,66 @@
 package model
 
-import "time"
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+)
 
 // SMSMessage represents a single SMS message.
 type SMSMessage struct {
-	Index    int       `json:"index"`
-	From     string    `json:"from,omitempty"`
-	To       string    `json:"to,omitempty"`
-	Content  string    `json:"content"`
-	SentTime time.Time `json:"sendTime,omitempty"`
-	RecvTime time.Time `json:"receivedTime,omitempty"`
-	Unread   bool      `json:"unread,omitempty"`
+	Index    int       `json:"index" yaml:"index"`
+	From     string    `json:"from,omitempty" yaml:"from,omitempty"`
+	To       string    `json:"to,omitempty" yaml:"to,omitempty"`
+	Content  string    `json:"content" yaml:"content"`
+	SentTime time.Time `json:"sendTime,omitempty" yaml:"sendTime,omitempty"`
+	RecvTime time.Time `json:"receivedTime,omitempty" yaml:"receivedTime,omitempty"`
+	Unread   bool      `json:"unread,omitempty" yaml:"unread,omitempty"`
+	Folder   string    `json:"folder,omitempty" yaml:"folder,omitempty"`
+	Host     string    `json:"host,omitempty" yaml:"host,omitempty"`
+	Hash     string    `json:"hash,omitempty" yaml:"hash,omitempty"`
+}
+
+// Time returns the received time for inbox messages, or the sent time.
+func (m SMSMessage) Time() time.Time {
+	if m.RecvTime.IsZero() {
+		return m.SentTime
+	}
+	return m.RecvTime
+}
+
+// ContentHash returns a stable hash of the message folder, numbers, time
+// and content. The router index and unread flag are not included, as
+// they change over the lifetime of a message. The time is hashed as the
+// wall clock time the router reports, so the hash doesn't depend on the
+// zone it is parsed in.
+func (m SMSMessage) ContentHash() string {
+	fields := []string{
+		m.Folder,
+		m.From,
+		m.To,
+		m.Time().Format("2006-01-02T15:04:05Z"),
+		m.Content,
+	}
+	sum := sha256.Sum256([]byte(strings.Join(fields, "\x00")))
+	return hex.EncodeToString(sum[:16])
+}
+
+// BoxInfo holds the message totals of an SMS folder.
+type BoxInfo struct {
+	Total   int `json:"total"`
+	PerPage int `json:"perPage"`
+	Page    int `json:"page"`
+	Unread  int `json:"unread"`
+}
+
+// Pages returns the number of pages in the folder.
+func (b BoxInfo) Pages() int {
+	perPage := b.PerPage
+	if perPage <= 0 {
+		perPage = 8
+	}
+	return (b.Total + perPage - 1) / perPage
 }
 
 // ListResponse is the response from List operation.
@@ -36,3 +86,16 @@ type SendResponse struct {
 	Error int          `json:"error"`
 	Data  []SMSMessage `json:"data"`
 }
+
+// SendRequest is the request for a Send operation.
+type SendRequest struct {
+	To      string `json:"to"`
+	Content string `json:"content"`
+}
+
+// ErrorResponse describes a failed gateway request, Error holds the
+// HTTP status code.
+type ErrorResponse struct {
+	Error   int    `json:"error"`
+	Message string `json:"message"`
+}
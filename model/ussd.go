@@ -0,0 +1,11 @@
+package model
+
+// USSDResponse is the network reply to a USSD request.
+type USSDResponse struct {
+	// Request is the code or menu choice which was sent.
+	Request string `json:"request" yaml:"request"`
+	Text    string `json:"text" yaml:"text"`
+
+	// Open is set if the session waits for a reply, as in a menu.
+	Open bool `json:"open" yaml:"open"`
+}
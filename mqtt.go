@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/titpetric/tp-link-cli/mqtt"
+	"github.com/titpetric/tp-link-cli/watch"
+)
+
+// MQTTBridge publishes new inbox messages to the broker and sends the
+// SMS requested over MQTT, until interrupted.
+func (c *SMSCommand) MQTTBridge(ctx context.Context) error {
+	if c.Broker == "" {
+		return fmt.Errorf("mqtt requires --broker=<host:port>")
+	}
+
+	opts := &mqtt.Options{Broker: c.Broker}
+	if c.MQTTAuth != "" {
+		username, password, ok := strings.Cut(c.MQTTAuth, ":")
+		if !ok {
+			return fmt.Errorf("invalid mqtt auth, expected <user:pass>")
+		}
+		opts.Username, opts.Password = username, password
+	}
+
+	topic := c.Topic
+	if topic == "" {
+		topic = mqtt.TopicPrefix(c.Host)
+	}
+
+	state, err := watch.LoadState(c.State)
+	if err != nil {
+		return fmt.Errorf("failed to load state: %w", err)
+	}
+
+	smsClient, err := c.NewClient()
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	bridge := &mqtt.Bridge{
+		Options:  opts,
+		Client:   smsClient,
+		Topic:    strings.TrimSuffix(topic, "/"),
+		State:    state,
+		Interval: c.Interval,
+		Backlog:  c.All,
+		Logf: func(format string, args ...interface{}) {
+			fmt.Fprintf(os.Stderr, "warning: "+format+"\n", args...)
+		},
+	}
+	fmt.Fprintf(os.Stderr, "Bridging %s to %s\n", topic, c.Broker)
+	err = bridge.Run(ctx)
+
+	// Log out with a fresh context, as ctx is cancelled on interrupt
+	c.CloseClient(context.Background(), smsClient)
+	return err
+}
@@ -0,0 +1,266 @@
+package mqtt
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/titpetric/tp-link-cli/client"
+	"github.com/titpetric/tp-link-cli/model"
+	"github.com/titpetric/tp-link-cli/watch"
+)
+
+// Bridge topics, below the topic prefix.
+const (
+	InboxTopic  = "/sms/inbox"
+	SendTopic   = "/sms/send"
+	ResultTopic = "/sms/send/result"
+)
+
+// Reconnect backoff limits.
+const (
+	minBackoff = time.Second
+	maxBackoff = 30 * time.Second
+)
+
+// SendRequest is a message on the send topic.
+type SendRequest struct {
+	ID   string `json:"id,omitempty"`
+	To   string `json:"to"`
+	Text string `json:"text"`
+}
+
+// SendResult is published on the result topic for every send request,
+// with the request ID for correlation.
+type SendResult struct {
+	ID    string `json:"id,omitempty"`
+	To    string `json:"to"`
+	Text  string `json:"text"`
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+}
+
+// Bridge publishes new inbox messages to <topic>/sms/inbox, and sends
+// the requests on <topic>/sms/send as SMS, publishing the results to
+// <topic>/sms/send/result. The router session is used by one request
+// at a time.
+type Bridge struct {
+	Options *Options
+	Client  *client.SMSClient
+
+	// Topic is the topic prefix, see TopicPrefix.
+	Topic string
+
+	// State, Interval and Backlog configure the inbox watch, as in
+	// watch.Watcher.
+	State    *watch.State
+	Interval time.Duration
+	Backlog  bool
+
+	// Logf reports connection and send errors, if set.
+	Logf func(format string, args ...interface{})
+
+	session sync.Mutex
+
+	connMu sync.Mutex
+	conn   *Conn
+}
+
+// TopicPrefix returns the default topic prefix for a router host,
+// tplink/<host>, with characters not allowed in topic levels replaced.
+func TopicPrefix(host string) string {
+	if u, err := url.Parse(host); err == nil && u.Host != "" {
+		host = u.Host
+	}
+	host = strings.NewReplacer("/", "_", "+", "_", "#", "_").Replace(host)
+	return "tplink/" + host
+}
+
+// Run connects to the broker and bridges messages until the context is
+// cancelled. A lost broker connection is re-established with backoff.
+func (b *Bridge) Run(ctx context.Context) error {
+	requests := make(chan Message, 16)
+
+	conn, err := b.connect(ctx, requests)
+	if err != nil {
+		return err
+	}
+	b.setConn(conn)
+
+	watcher := &watch.Watcher{
+		Client:   b.Client,
+		State:    b.State,
+		Interval: b.Interval,
+		Backlog:  b.Backlog,
+		Logf:     b.Logf,
+		Lock:     &b.session,
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	errc := make(chan error, 1)
+	go func() {
+		errc <- watcher.Run(ctx, b.publishMessage)
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			b.current().Close()
+			return <-errc
+		case err := <-errc:
+			b.current().Close()
+			return err
+		case req := <-requests:
+			b.handleSend(ctx, req.Payload)
+		case <-b.current().Done():
+			b.logf("%v, reconnecting", b.current().Err())
+			conn, err := b.reconnect(ctx, requests)
+			if err != nil {
+				cancel()
+				return <-errc
+			}
+			b.setConn(conn)
+		}
+	}
+}
+
+// connect dials the broker and subscribes to the send topic, queueing
+// send requests to requests.
+func (b *Bridge) connect(ctx context.Context, requests chan<- Message) (*Conn, error) {
+	conn, err := Dial(ctx, b.Options, func(msg Message) {
+		select {
+		case requests <- msg:
+		default:
+			b.logf("dropped send request, too many pending")
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := conn.Subscribe(ctx, b.Topic+SendTopic); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to subscribe: %w", err)
+	}
+	return conn, nil
+}
+
+// reconnect connects with exponential backoff until it succeeds, or the
+// context is cancelled.
+func (b *Bridge) reconnect(ctx context.Context, requests chan<- Message) (*Conn, error) {
+	backoff := minBackoff
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(backoff):
+		}
+
+		conn, err := b.connect(ctx, requests)
+		if err == nil {
+			b.logf("reconnected to %s", b.Options.Broker)
+			return conn, nil
+		}
+		b.logf("%v", err)
+
+		backoff = min(backoff*2, maxBackoff)
+	}
+}
+
+func (b *Bridge) current() *Conn {
+	b.connMu.Lock()
+	defer b.connMu.Unlock()
+	return b.conn
+}
+
+func (b *Bridge) setConn(conn *Conn) {
+	b.connMu.Lock()
+	defer b.connMu.Unlock()
+	b.conn = conn
+}
+
+// publishMessage publishes an inbox message at QoS 1, waiting for the
+// broker connection to come back if it is lost. The message is only
+// marked seen once the broker acknowledges it, so it is published again
+// when the connection is lost before the acknowledgement.
+func (b *Bridge) publishMessage(ctx context.Context, msg model.SMSMessage) error {
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+
+	for {
+		err := b.current().PublishAck(ctx, b.Topic+InboxTopic, payload, false)
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(minBackoff):
+		}
+	}
+}
+
+// handleSend sends the SMS of a send request and publishes the result.
+func (b *Bridge) handleSend(ctx context.Context, payload []byte) {
+	var req SendRequest
+	result := SendResult{}
+
+	if err := json.Unmarshal(payload, &req); err != nil {
+		result.Error = "invalid request: " + err.Error()
+	} else {
+		result.ID, result.To, result.Text = req.ID, req.To, req.Text
+		if err := b.send(ctx, req); err != nil {
+			result.Error = err.Error()
+		} else {
+			result.OK = true
+		}
+	}
+
+	if result.Error != "" {
+		b.logf("failed to send SMS to %s: %s", result.To, result.Error)
+	}
+
+	body, err := json.Marshal(result)
+	if err != nil {
+		return
+	}
+	if err := b.current().Publish(b.Topic+ResultTopic, body, false); err != nil {
+		b.logf("failed to publish send result: %v", err)
+	}
+}
+
+func (b *Bridge) send(ctx context.Context, req SendRequest) error {
+	if req.To == "" || req.Text == "" {
+		return fmt.Errorf("to and text are required")
+	}
+
+	b.session.Lock()
+	defer b.session.Unlock()
+
+	resp, err := b.Client.Send(ctx, req.To, req.Text)
+	if err != nil {
+		return err
+	}
+	if resp.Error != 0 {
+		return fmt.Errorf("router returned error code: %d", resp.Error)
+	}
+	return nil
+}
+
+func (b *Bridge) logf(format string, args ...interface{}) {
+	if b.Logf != nil {
+		b.Logf(format, args...)
+	}
+}
@@ -0,0 +1,212 @@
+package mqtt
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/titpetric/tp-link-cli/client"
+	"github.com/titpetric/tp-link-cli/fakerouter"
+	"github.com/titpetric/tp-link-cli/model"
+	"github.com/titpetric/tp-link-cli/watch"
+)
+
+const testTopic = "tplink/router"
+
+// startBridge runs a bridge to a fake router through the test broker,
+// and returns a subscriber of the inbox and result topics.
+func startBridge(t *testing.T, broker *testBroker, router *fakerouter.Router) (*Conn, <-chan Message) {
+	t.Helper()
+
+	c, err := client.NewSMSClient(&client.Options{Auth: "admin:secret", Host: router.URL()})
+	require.NoError(t, err)
+
+	state, err := watch.LoadState("")
+	require.NoError(t, err)
+
+	bridge := &Bridge{
+		Options:  &Options{Broker: broker.addr},
+		Client:   c,
+		Topic:    testTopic,
+		State:    state,
+		Interval: 20 * time.Millisecond,
+		Backlog:  true,
+		Logf:     t.Logf,
+	}
+
+	received := make(chan Message, 16)
+	sub, err := Dial(context.Background(), &Options{Broker: broker.addr}, func(msg Message) {
+		received <- msg
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { sub.Close() })
+	require.NoError(t, sub.Subscribe(context.Background(), testTopic+InboxTopic, testTopic+ResultTopic))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- bridge.Run(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		require.NoError(t, <-done)
+	})
+
+	return sub, received
+}
+
+func receive(t *testing.T, received <-chan Message, topic string) []byte {
+	t.Helper()
+	for {
+		select {
+		case msg := <-received:
+			if msg.Topic == topic {
+				return msg.Payload
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("no message on %s", topic)
+		}
+	}
+}
+
+func TestBridgeInbox(t *testing.T) {
+	broker := newTestBroker(t)
+	router := fakerouter.New("admin", "secret")
+	defer router.Close()
+	router.Receive("13909", "BRZINA", time.Now())
+
+	_, received := startBridge(t, broker, router)
+
+	var msg model.SMSMessage
+	require.NoError(t, json.Unmarshal(receive(t, received, testTopic+InboxTopic), &msg))
+	assert.Equal(t, "13909", msg.From)
+	assert.Equal(t, "BRZINA", msg.Content)
+
+	router.Receive("13910", "second", time.Now())
+	require.NoError(t, json.Unmarshal(receive(t, received, testTopic+InboxTopic), &msg))
+	assert.Equal(t, "second", msg.Content)
+}
+
+func TestBridgeSend(t *testing.T) {
+	broker := newTestBroker(t)
+	router := fakerouter.New("admin", "secret")
+	defer router.Close()
+
+	sub, received := startBridge(t, broker, router)
+
+	require.Eventually(t, func() bool { return broker.Subscribers(testTopic+SendTopic) == 1 }, 5*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, sub.Publish(testTopic+SendTopic, []byte(`{"id":"1","to":"13909","text":"brzina"}`), false))
+
+	var result SendResult
+	require.NoError(t, json.Unmarshal(receive(t, received, testTopic+ResultTopic), &result))
+	assert.Equal(t, SendResult{ID: "1", To: "13909", Text: "brzina", OK: true}, result)
+
+	sent := router.Sent()
+	require.Len(t, sent, 1)
+	assert.Equal(t, "13909", sent[0].To)
+	assert.Equal(t, "brzina", sent[0].Content)
+
+	require.NoError(t, sub.Publish(testTopic+SendTopic, []byte(`{"id":"2","to":"13909"}`), false))
+	require.NoError(t, json.Unmarshal(receive(t, received, testTopic+ResultTopic), &result))
+	assert.Equal(t, "2", result.ID)
+	assert.False(t, result.OK)
+	assert.NotEmpty(t, result.Error)
+	assert.Len(t, router.Sent(), 1)
+}
+
+func TestBridgeReconnect(t *testing.T) {
+	broker := newTestBroker(t)
+	router := fakerouter.New("admin", "secret")
+	defer router.Close()
+
+	startBridge(t, broker, router)
+	require.Eventually(t, func() bool { return broker.Subscribers(testTopic+SendTopic) == 1 }, 5*time.Second, 10*time.Millisecond)
+
+	broker.Disconnect()
+
+	received := make(chan Message, 16)
+	sub, err := Dial(context.Background(), &Options{Broker: broker.addr}, func(msg Message) {
+		received <- msg
+	})
+	require.NoError(t, err)
+	defer sub.Close()
+	require.NoError(t, sub.Subscribe(context.Background(), testTopic+InboxTopic))
+
+	// The bridge reconnects after the backoff
+	require.Eventually(t, func() bool { return broker.Subscribers(testTopic+SendTopic) == 1 }, 5*time.Second, 10*time.Millisecond)
+
+	router.Receive("13909", "after reconnect", time.Now())
+
+	var msg model.SMSMessage
+	require.NoError(t, json.Unmarshal(receive(t, received, testTopic+InboxTopic), &msg))
+	assert.Equal(t, "after reconnect", msg.Content)
+}
+
+func TestBridgeInboxUnacknowledged(t *testing.T) {
+	broker := newTestBroker(t)
+	router := fakerouter.New("admin", "secret")
+	defer router.Close()
+
+	startBridge(t, broker, router)
+	require.Eventually(t, func() bool { return broker.Subscribers(testTopic+SendTopic) == 1 }, 5*time.Second, 10*time.Millisecond)
+
+	// The broker never acknowledges the message before the connection drops
+	broker.Drop(true)
+	router.Receive("13909", "unacknowledged", time.Now())
+	require.Eventually(t, func() bool { return broker.Dropped() == 1 }, 5*time.Second, 10*time.Millisecond)
+	broker.Drop(false)
+	broker.Disconnect()
+
+	received := make(chan Message, 16)
+	sub, err := Dial(context.Background(), &Options{Broker: broker.addr}, func(msg Message) {
+		received <- msg
+	})
+	require.NoError(t, err)
+	defer sub.Close()
+	require.NoError(t, sub.Subscribe(context.Background(), testTopic+InboxTopic))
+
+	// The message was not marked seen, so it is published after reconnecting
+	var msg model.SMSMessage
+	require.NoError(t, json.Unmarshal(receive(t, received, testTopic+InboxTopic), &msg))
+	assert.Equal(t, "unacknowledged", msg.Content)
+}
+
+func TestPublishAck(t *testing.T) {
+	broker := newTestBroker(t)
+
+	conn, err := Dial(context.Background(), &Options{Broker: broker.addr}, nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	require.NoError(t, conn.PublishAck(context.Background(), testTopic, []byte("acked"), false))
+
+	broker.Drop(true)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, conn.PublishAck(ctx, testTopic, []byte("dropped"), false), context.DeadlineExceeded)
+}
+
+func TestTopicPrefix(t *testing.T) {
+	assert.Equal(t, "tplink/192.168.1.1", TopicPrefix("192.168.1.1"))
+	assert.Equal(t, "tplink/127.0.0.1:8080", TopicPrefix("http://127.0.0.1:8080"))
+	assert.Equal(t, "tplink/a_b", TopicPrefix("a+b"))
+}
+
+func TestParseBroker(t *testing.T) {
+	addr, useTLS := parseBroker("localhost")
+	assert.Equal(t, "localhost:1883", addr)
+	assert.False(t, useTLS)
+
+	addr, useTLS = parseBroker("ssl://broker.example.com")
+	assert.Equal(t, "broker.example.com:8883", addr)
+	assert.True(t, useTLS)
+
+	addr, useTLS = parseBroker("tcp://10.0.0.2:1884")
+	assert.Equal(t, "10.0.0.2:1884", addr)
+	assert.False(t, useTLS)
+}
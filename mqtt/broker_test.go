@@ -0,0 +1,172 @@
+package mqtt
+
+import (
+	"bufio"
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testBroker is an in-process MQTT broker for tests. It routes messages
+// to subscribers of the exact topic at QoS 0, and acknowledges QoS 1
+// messages.
+type testBroker struct {
+	addr string
+
+	mu    sync.Mutex
+	conns map[net.Conn]map[string]bool
+
+	// dropped counts QoS 1 messages dropped without acknowledgement
+	// while dropping is set, as on a half-open connection.
+	dropping bool
+	dropped  int
+}
+
+func newTestBroker(t *testing.T) *testBroker {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	b := &testBroker{
+		addr:  l.Addr().String(),
+		conns: map[net.Conn]map[string]bool{},
+	}
+	t.Cleanup(func() {
+		l.Close()
+		b.Disconnect()
+	})
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go b.serve(conn)
+		}
+	}()
+	return b
+}
+
+// Disconnect drops all client connections.
+func (b *testBroker) Disconnect() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for conn := range b.conns {
+		conn.Close()
+	}
+}
+
+// Drop sets whether QoS 1 messages are dropped without acknowledgement.
+func (b *testBroker) Drop(drop bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.dropping = drop
+}
+
+// Dropped returns the number of dropped messages.
+func (b *testBroker) Dropped() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.dropped
+}
+
+// Subscribers returns the number of clients subscribed to the topic.
+func (b *testBroker) Subscribers(topic string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	n := 0
+	for _, topics := range b.conns {
+		if topics[topic] {
+			n++
+		}
+	}
+	return n
+}
+
+func (b *testBroker) serve(conn net.Conn) {
+	defer func() {
+		b.mu.Lock()
+		delete(b.conns, conn)
+		b.mu.Unlock()
+		conn.Close()
+	}()
+
+	r := bufio.NewReader(conn)
+	p, err := readPacket(r)
+	if err != nil || p.kind != packetConnect {
+		return
+	}
+	b.mu.Lock()
+	b.conns[conn] = map[string]bool{}
+	writePacket(conn, packetConnack, 0, []byte{0, 0})
+	b.mu.Unlock()
+
+	for {
+		p, err := readPacket(r)
+		if err != nil {
+			return
+		}
+
+		switch p.kind {
+		case packetSubscribe:
+			id, rest, err := readUint16(p.body)
+			if err != nil {
+				return
+			}
+			var codes []byte
+			b.mu.Lock()
+			for len(rest) > 0 {
+				var topic string
+				topic, rest, err = readString(rest)
+				if err != nil || len(rest) == 0 {
+					b.mu.Unlock()
+					return
+				}
+				rest = rest[1:]
+				b.conns[conn][topic] = true
+				codes = append(codes, 0)
+			}
+			writePacket(conn, packetSuback, 0, append([]byte{byte(id >> 8), byte(id)}, codes...))
+			b.mu.Unlock()
+		case packetPublish:
+			topic, id, payload, err := parsePublish(p)
+			if err != nil {
+				return
+			}
+			if qos := (p.flags >> 1) & 0x03; qos == 1 {
+				b.mu.Lock()
+				if b.dropping {
+					b.dropped++
+					b.mu.Unlock()
+					continue
+				}
+				writePacket(conn, packetPuback, 0, []byte{byte(id >> 8), byte(id)})
+				b.mu.Unlock()
+			}
+			b.Publish(topic, payload)
+		case packetPingreq:
+			b.mu.Lock()
+			writePacket(conn, packetPingresp, 0, nil)
+			b.mu.Unlock()
+		case packetDisconnect:
+			return
+		}
+	}
+}
+
+// Publish routes a message to the subscribers of the topic.
+func (b *testBroker) Publish(topic string, payload []byte) {
+	body := append(appendString(nil, topic), payload...)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for conn, topics := range b.conns {
+		if topics[topic] {
+			writePacket(conn, packetPublish, 0, body)
+		}
+	}
+}
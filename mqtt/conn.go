@@ -0,0 +1,384 @@
+// Package mqtt bridges SMS and MQTT, with a minimal MQTT 3.1.1 client
+// that subscribes at QoS 0 and publishes at QoS 0 or 1.
+package mqtt
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultKeepAlive is the keep alive interval used when none is set.
+const DefaultKeepAlive = 30 * time.Second
+
+// ErrClosed is returned when using a closed connection.
+var ErrClosed = errors.New("mqtt: connection closed")
+
+// Options configure the broker connection.
+type Options struct {
+	// Broker is the broker address, as host:port, tcp://host:port or
+	// ssl://host:port for TLS.
+	Broker   string
+	ClientID string
+	Username string
+	Password string
+
+	// KeepAlive is the interval of pings to the broker, 30s if zero.
+	KeepAlive time.Duration
+}
+
+// Message is a message received on a subscribed topic.
+type Message struct {
+	Topic   string
+	Payload []byte
+}
+
+// Conn is a connection to an MQTT broker.
+type Conn struct {
+	conn      net.Conn
+	r         *bufio.Reader
+	keepAlive time.Duration
+	handler   func(Message)
+
+	writeMu sync.Mutex
+
+	mu      sync.Mutex
+	nextID  uint16
+	pending map[uint16]chan []byte
+	err     error
+
+	done chan struct{}
+}
+
+// Dial connects to the broker, calling handler for every message on
+// the subscribed topics. The handler is called from the read loop, and
+// should not block.
+func Dial(ctx context.Context, opts *Options, handler func(Message)) (*Conn, error) {
+	addr, useTLS := parseBroker(opts.Broker)
+
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	netConn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to broker: %w", err)
+	}
+	if useTLS {
+		host, _, _ := net.SplitHostPort(addr)
+		netConn = tls.Client(netConn, &tls.Config{ServerName: host})
+	}
+
+	keepAlive := opts.KeepAlive
+	if keepAlive <= 0 {
+		keepAlive = DefaultKeepAlive
+	}
+
+	c := &Conn{
+		conn:      netConn,
+		r:         bufio.NewReader(netConn),
+		keepAlive: keepAlive,
+		handler:   handler,
+		pending:   map[uint16]chan []byte{},
+		done:      make(chan struct{}),
+	}
+
+	if err := c.connect(opts); err != nil {
+		netConn.Close()
+		return nil, err
+	}
+
+	go c.readLoop()
+	go c.pingLoop()
+	return c, nil
+}
+
+// parseBroker returns the address of a broker, and whether it uses TLS.
+func parseBroker(broker string) (string, bool) {
+	scheme, addr, ok := strings.Cut(broker, "://")
+	if !ok {
+		addr, scheme = broker, "tcp"
+	}
+	useTLS := scheme == "ssl" || scheme == "tls" || scheme == "mqtts"
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		if useTLS {
+			addr = net.JoinHostPort(addr, "8883")
+		} else {
+			addr = net.JoinHostPort(addr, "1883")
+		}
+	}
+	return addr, useTLS
+}
+
+func (c *Conn) connect(opts *Options) error {
+	clientID := opts.ClientID
+	if clientID == "" {
+		clientID = NewClientID()
+	}
+
+	flags := byte(connectCleanStart)
+	if opts.Username != "" {
+		flags |= connectUsername
+	}
+	if opts.Password != "" {
+		flags |= connectPassword
+	}
+
+	body := appendString(nil, "MQTT")
+	body = append(body, protocolLevel, flags)
+	body = binary.BigEndian.AppendUint16(body, uint16(c.keepAlive/time.Second))
+	body = appendString(body, clientID)
+	if opts.Username != "" {
+		body = appendString(body, opts.Username)
+	}
+	if opts.Password != "" {
+		body = appendString(body, opts.Password)
+	}
+
+	c.conn.SetDeadline(time.Now().Add(10 * time.Second))
+	defer c.conn.SetDeadline(time.Time{})
+
+	if err := writePacket(c.conn, packetConnect, 0, body); err != nil {
+		return fmt.Errorf("failed to send connect: %w", err)
+	}
+
+	p, err := readPacket(c.r)
+	if err != nil {
+		return fmt.Errorf("failed to read connack: %w", err)
+	}
+	if p.kind != packetConnack || len(p.body) < 2 {
+		return fmt.Errorf("expected connack, got packet type %d", p.kind)
+	}
+	if code := p.body[1]; code != 0 {
+		return fmt.Errorf("broker refused connection: %s", connackError(code))
+	}
+	return nil
+}
+
+func connackError(code byte) string {
+	switch code {
+	case 1:
+		return "unacceptable protocol version"
+	case 2:
+		return "identifier rejected"
+	case 3:
+		return "server unavailable"
+	case 4:
+		return "bad user name or password"
+	case 5:
+		return "not authorized"
+	}
+	return fmt.Sprintf("return code %d", code)
+}
+
+// NewClientID returns a random client ID.
+func NewClientID() string {
+	b := make([]byte, 6)
+	rand.Read(b)
+	return "tp-link-cli-" + hex.EncodeToString(b)
+}
+
+// Subscribe subscribes to the topics at QoS 0, and waits for the broker
+// to acknowledge the subscription.
+func (c *Conn) Subscribe(ctx context.Context, topics ...string) error {
+	id, ack := c.register()
+	defer c.release(id)
+
+	body := []byte{byte(id >> 8), byte(id)}
+	for _, topic := range topics {
+		body = appendString(body, topic)
+		body = append(body, 0)
+	}
+	if err := c.write(packetSubscribe, 0x02, body); err != nil {
+		return err
+	}
+
+	select {
+	case codes := <-ack:
+		for i, code := range codes {
+			if code == 0x80 && i < len(topics) {
+				return fmt.Errorf("broker rejected subscription to %s", topics[i])
+			}
+		}
+		return nil
+	case <-c.done:
+		return c.Err()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Publish sends a message to the topic at QoS 0.
+func (c *Conn) Publish(topic string, payload []byte, retain bool) error {
+	var flags byte
+	if retain {
+		flags = 0x01
+	}
+	body := appendString(nil, topic)
+	return c.write(packetPublish, flags, append(body, payload...))
+}
+
+// PublishAck sends a message to the topic at QoS 1, and waits for the
+// broker to acknowledge it. A message without an acknowledgement may
+// not have reached the broker, and should be published again.
+func (c *Conn) PublishAck(ctx context.Context, topic string, payload []byte, retain bool) error {
+	id, ack := c.register()
+	defer c.release(id)
+
+	flags := byte(0x02)
+	if retain {
+		flags |= 0x01
+	}
+	body := appendString(nil, topic)
+	body = append(body, byte(id>>8), byte(id))
+	if err := c.write(packetPublish, flags, append(body, payload...)); err != nil {
+		return err
+	}
+
+	select {
+	case <-ack:
+		return nil
+	case <-c.done:
+		return c.Err()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// register allocates a packet ID, returning the channel receiving the
+// body of its acknowledgement.
+func (c *Conn) register() (uint16, chan []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.nextID++
+	if c.nextID == 0 {
+		c.nextID = 1
+	}
+	ack := make(chan []byte, 1)
+	c.pending[c.nextID] = ack
+	return c.nextID, ack
+}
+
+func (c *Conn) release(id uint16) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.pending, id)
+}
+
+// Done is closed when the connection is lost or closed.
+func (c *Conn) Done() <-chan struct{} {
+	return c.done
+}
+
+// Err returns the reason the connection was lost.
+func (c *Conn) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// Close disconnects from the broker.
+func (c *Conn) Close() error {
+	c.write(packetDisconnect, 0, nil)
+	c.fail(ErrClosed)
+	return nil
+}
+
+func (c *Conn) write(kind, flags byte, body []byte) error {
+	select {
+	case <-c.done:
+		return c.Err()
+	default:
+	}
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	c.conn.SetWriteDeadline(time.Now().Add(c.keepAlive))
+	if err := writePacket(c.conn, kind, flags, body); err != nil {
+		c.fail(err)
+		return err
+	}
+	return nil
+}
+
+// fail closes the connection, recording the first error.
+func (c *Conn) fail(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	select {
+	case <-c.done:
+		return
+	default:
+	}
+	c.err = err
+	close(c.done)
+	c.conn.Close()
+}
+
+func (c *Conn) readLoop() {
+	for {
+		// The broker answers pings, so silence means a dead connection
+		c.conn.SetReadDeadline(time.Now().Add(c.keepAlive * 3 / 2))
+
+		p, err := readPacket(c.r)
+		if err != nil {
+			c.fail(fmt.Errorf("mqtt: connection lost: %w", err))
+			return
+		}
+
+		switch p.kind {
+		case packetPublish:
+			topic, id, payload, err := parsePublish(p)
+			if err != nil {
+				c.fail(err)
+				return
+			}
+			if qos := (p.flags >> 1) & 0x03; qos == 1 {
+				c.write(packetPuback, 0, []byte{byte(id >> 8), byte(id)})
+			}
+			if c.handler != nil {
+				c.handler(Message{Topic: topic, Payload: payload})
+			}
+		case packetSuback, packetPuback:
+			id, codes, err := readUint16(p.body)
+			if err != nil {
+				c.fail(err)
+				return
+			}
+			c.mu.Lock()
+			ack := c.pending[id]
+			c.mu.Unlock()
+			if ack != nil {
+				select {
+				case ack <- codes:
+				default:
+				}
+			}
+		}
+	}
+}
+
+func (c *Conn) pingLoop() {
+	ticker := time.NewTicker(c.keepAlive / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+			if err := c.write(packetPingreq, 0, nil); err != nil {
+				return
+			}
+		}
+	}
+}
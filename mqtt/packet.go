@@ -0,0 +1,132 @@
+package mqtt
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// MQTT 3.1.1 control packet types.
+const (
+	packetConnect    = 1
+	packetConnack    = 2
+	packetPublish    = 3
+	packetPuback     = 4
+	packetSubscribe  = 8
+	packetSuback     = 9
+	packetPingreq    = 12
+	packetPingresp   = 13
+	packetDisconnect = 14
+)
+
+// CONNECT flags and limits.
+const (
+	protocolLevel     = 4
+	connectCleanStart = 0x02
+	connectPassword   = 0x40
+	connectUsername   = 0x80
+	maxPacketSize     = 256 * 1024
+)
+
+var errMalformed = errors.New("malformed packet")
+
+// packet is a control packet, the fixed header type and flags with the
+// variable header and payload in body.
+type packet struct {
+	kind  byte
+	flags byte
+	body  []byte
+}
+
+func readPacket(r *bufio.Reader) (packet, error) {
+	header, err := r.ReadByte()
+	if err != nil {
+		return packet{}, err
+	}
+
+	// The remaining length is a varint of up to 4 bytes
+	length, shift := 0, 0
+	for i := 0; ; i++ {
+		if i == 4 {
+			return packet{}, errMalformed
+		}
+		b, err := r.ReadByte()
+		if err != nil {
+			return packet{}, err
+		}
+		length |= int(b&0x7f) << shift
+		shift += 7
+		if b&0x80 == 0 {
+			break
+		}
+	}
+	if length > maxPacketSize {
+		return packet{}, fmt.Errorf("packet of %d bytes exceeds limit", length)
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return packet{}, err
+	}
+	return packet{kind: header >> 4, flags: header & 0x0f, body: body}, nil
+}
+
+func writePacket(w io.Writer, kind, flags byte, body []byte) error {
+	buf := []byte{kind<<4 | flags&0x0f}
+	length := len(body)
+	for {
+		b := byte(length & 0x7f)
+		length >>= 7
+		if length > 0 {
+			b |= 0x80
+		}
+		buf = append(buf, b)
+		if length == 0 {
+			break
+		}
+	}
+	_, err := w.Write(append(buf, body...))
+	return err
+}
+
+func appendString(b []byte, s string) []byte {
+	b = binary.BigEndian.AppendUint16(b, uint16(len(s)))
+	return append(b, s...)
+}
+
+func readString(b []byte) (string, []byte, error) {
+	if len(b) < 2 {
+		return "", nil, errMalformed
+	}
+	n := int(binary.BigEndian.Uint16(b))
+	if len(b) < 2+n {
+		return "", nil, errMalformed
+	}
+	return string(b[2 : 2+n]), b[2+n:], nil
+}
+
+func readUint16(b []byte) (uint16, []byte, error) {
+	if len(b) < 2 {
+		return 0, nil, errMalformed
+	}
+	return binary.BigEndian.Uint16(b), b[2:], nil
+}
+
+// parsePublish returns the topic, packet ID (for QoS 1 and 2) and
+// payload of a PUBLISH packet.
+func parsePublish(p packet) (string, uint16, []byte, error) {
+	topic, rest, err := readString(p.body)
+	if err != nil {
+		return "", 0, nil, err
+	}
+	var id uint16
+	if qos := (p.flags >> 1) & 0x03; qos > 0 {
+		id, rest, err = readUint16(rest)
+		if err != nil {
+			return "", 0, nil, err
+		}
+	}
+	return topic, id, rest, nil
+}
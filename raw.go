@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/titpetric/tp-link-cli/client"
+)
+
+// Raw sends a single request to any router controller and prints the
+// decoded response as JSON
+func (c *SMSCommand) Raw(ctx context.Context) error {
+	if c.Controller == "" {
+		return fmt.Errorf("raw requires --controller=<name>")
+	}
+
+	methodName := c.Method
+	if methodName == "" {
+		methodName = "get"
+	}
+	method, err := client.ParseMethod(methodName)
+	if err != nil {
+		return err
+	}
+
+	smsClient, err := c.NewClient()
+	if err != nil {
+		return err
+	}
+	defer c.CloseClient(ctx, smsClient)
+
+	resp, err := smsClient.Do(ctx, []client.Request{{
+		Method:     method,
+		Controller: c.Controller,
+		Stack:      c.Stack,
+		Attrs:      rawAttrs(c.Attrs),
+	}})
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(resp); err != nil {
+		return err
+	}
+
+	if resp.Error != 0 {
+		return fmt.Errorf("router returned error code: %d", resp.Error)
+	}
+	return nil
+}
+
+// rawAttrs returns the request attributes, a list of names to get, or
+// name=value pairs to set when any attribute has a value
+func rawAttrs(attrs []string) interface{} {
+	values := map[string]interface{}{}
+	hasValues := false
+	for _, attr := range attrs {
+		name, value, ok := strings.Cut(attr, "=")
+		if ok {
+			values[name] = value
+			hasValues = true
+			continue
+		}
+		values[name] = nil
+	}
+
+	if hasValues {
+		return values
+	}
+	return attrs
+}
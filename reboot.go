@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/titpetric/tp-link-cli/client"
+)
+
+// Reboot wait defaults
+const (
+	defaultRebootTimeout  = 5 * time.Minute
+	defaultRebootInterval = 2 * time.Second
+)
+
+// Exit codes of the reboot command
+const (
+	exitRebootFailed  = 1
+	exitRebootNotDown = 2
+	exitRebootNotUp   = 3
+)
+
+var (
+	errRebootNotDown = errors.New("router did not go down")
+	errRebootNotUp   = errors.New("router did not come back")
+)
+
+// Reboot restarts the router. With --wait, it waits for the router to go
+// down and come back up with a working login, within --timeout.
+func (c *SMSCommand) Reboot(ctx context.Context) error {
+	smsClient, err := c.NewClient()
+	if err != nil {
+		return err
+	}
+
+	// Log in first, so a failed reboot request is known to be the reboot
+	if err := smsClient.Connect(ctx); err != nil {
+		return fmt.Errorf("failed to log in: %w", err)
+	}
+
+	err = smsClient.Reboot(ctx)
+	switch {
+	case err == nil:
+	case c.Wait && ctx.Err() == nil && !errors.Is(err, client.ErrRebootRejected):
+		// The router may drop the connection as it restarts
+		fmt.Fprintf(os.Stderr, "warning: %v, waiting for the reboot\n", err)
+	default:
+		return fmt.Errorf("failed to reboot: %w", err)
+	}
+	fmt.Printf("Rebooting %s\n", c.Host)
+
+	if !c.Wait {
+		return nil
+	}
+
+	timeout := c.Timeout
+	if timeout == 0 {
+		timeout = defaultRebootTimeout
+	}
+	interval := c.Interval
+	if interval == 0 {
+		interval = defaultRebootInterval
+	}
+
+	start := time.Now()
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	if err := waitReboot(ctx, smsClient, interval); err != nil {
+		return err
+	}
+	defer c.CloseClient(context.Background(), smsClient)
+
+	fmt.Printf("Router is back after %s\n", time.Since(start).Round(time.Second))
+	return nil
+}
+
+// waitReboot polls the router until it stops answering, then until it
+// answers again and a login succeeds.
+func waitReboot(ctx context.Context, smsClient *client.SMSClient, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for smsClient.Ping(ctx) == nil {
+		select {
+		case <-ctx.Done():
+			return errRebootNotDown
+		case <-ticker.C:
+		}
+	}
+	if ctx.Err() != nil {
+		// The ping failed on the timeout, not on the router
+		return errRebootNotDown
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return errRebootNotUp
+		case <-ticker.C:
+		}
+
+		// The web interface answers before the login works
+		if smsClient.Ping(ctx) == nil && smsClient.Connect(ctx) == nil {
+			return nil
+		}
+	}
+}
+
+// rebootExitCode returns the exit code for a reboot error
+func rebootExitCode(err error) int {
+	switch {
+	case errors.Is(err, errRebootNotDown):
+		return exitRebootNotDown
+	case errors.Is(err, errRebootNotUp):
+		return exitRebootNotUp
+	}
+	return exitRebootFailed
+}
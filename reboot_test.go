@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/titpetric/tp-link-cli/client"
+	"github.com/titpetric/tp-link-cli/fakerouter"
+)
+
+func TestWaitReboot(t *testing.T) {
+	router := fakerouter.New("admin", "secret")
+	defer router.Close()
+	router.RebootDelay = 200 * time.Millisecond
+
+	smsClient, err := client.NewSMSClient(&client.Options{Auth: "admin:secret", Host: router.URL()})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	require.NoError(t, smsClient.Reboot(ctx))
+	require.NoError(t, waitReboot(ctx, smsClient, 20*time.Millisecond))
+	assert.NotEmpty(t, smsClient.TokenID)
+	assert.True(t, router.LoggedIn())
+}
+
+func TestWaitRebootTimeout(t *testing.T) {
+	router := fakerouter.New("admin", "secret")
+	defer router.Close()
+
+	smsClient, err := client.NewSMSClient(&client.Options{Auth: "admin:secret", Host: router.URL()})
+	require.NoError(t, err)
+
+	// The router never goes down
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	err = waitReboot(ctx, smsClient, 20*time.Millisecond)
+	assert.ErrorIs(t, err, errRebootNotDown)
+	assert.Equal(t, exitRebootNotDown, rebootExitCode(err))
+
+	router.RebootDelay = time.Hour
+	require.NoError(t, smsClient.Reboot(context.Background()))
+
+	ctx, cancel = context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	err = waitReboot(ctx, smsClient, 20*time.Millisecond)
+	assert.ErrorIs(t, err, errRebootNotUp)
+	assert.Equal(t, exitRebootNotUp, rebootExitCode(err))
+}
+
+func TestRebootTruncatedResponse(t *testing.T) {
+	router := fakerouter.New("admin", "secret")
+	defer router.Close()
+	router.RebootDelay = 200 * time.Millisecond
+
+	c := &SMSCommand{
+		Auth:     "admin:secret",
+		Host:     router.URL(),
+		Wait:     true,
+		Timeout:  5 * time.Second,
+		Interval: 20 * time.Millisecond,
+	}
+
+	// The router restarts before its response is complete
+	router.CorruptNextResponse()
+	require.NoError(t, c.Reboot(context.Background()))
+	assert.Equal(t, 1, router.Reboots())
+
+	// Without --wait, the error is reported
+	c.Wait = false
+	router.CorruptNextResponse()
+	err := c.Reboot(context.Background())
+	assert.ErrorContains(t, err, "failed to reboot")
+	assert.Equal(t, exitRebootFailed, rebootExitCode(err))
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// secretReader reads PINs and passwords from the environment or stdin,
+// so they are not visible in the process list
+type secretReader struct {
+	input *bufio.Reader
+
+	// terminal is set when stdin is the terminal fd, which is read
+	// without echo
+	terminal bool
+	fd       int
+}
+
+func newSecretReader(f *os.File) *secretReader {
+	fd := int(f.Fd())
+	return &secretReader{
+		input:    bufio.NewReader(f),
+		terminal: term.IsTerminal(fd),
+		fd:       fd,
+	}
+}
+
+// read returns the secret from the environment variable, or the next
+// line of stdin. On a terminal it prompts, and the secret is not echoed.
+func (s *secretReader) read(env, prompt string) (string, error) {
+	if secret := os.Getenv(env); secret != "" {
+		return secret, nil
+	}
+
+	if s.terminal {
+		fmt.Fprintf(os.Stderr, "%s: ", prompt)
+		secret, err := term.ReadPassword(s.fd)
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return "", fmt.Errorf("failed to read %s: %w", prompt, err)
+		}
+		return string(secret), nil
+	}
+
+	line, err := s.input.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", fmt.Errorf("no %s given, set %s or pass it on stdin", prompt, env)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecretReader(t *testing.T) {
+	pins := &secretReader{input: bufio.NewReader(strings.NewReader("1234\n5678"))}
+
+	pin, err := pins.read("TP_LINK_CLI_TEST_PIN", "PIN")
+	require.NoError(t, err)
+	assert.Equal(t, "1234", pin)
+
+	t.Setenv("TP_LINK_CLI_TEST_PIN", "0000")
+	pin, err = pins.read("TP_LINK_CLI_TEST_PIN", "PIN")
+	require.NoError(t, err)
+	assert.Equal(t, "0000", pin)
+
+	pin, err = pins.read("TP_LINK_CLI_TEST_NEW_PIN", "New PIN")
+	require.NoError(t, err)
+	assert.Equal(t, "5678", pin)
+
+	_, err = pins.read("TP_LINK_CLI_TEST_NEW_PIN", "New PIN")
+	assert.ErrorContains(t, err, "TP_LINK_CLI_TEST_NEW_PIN")
+}
+
+func TestSecretReaderPipe(t *testing.T) {
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	defer r.Close()
+
+	_, err = w.WriteString("1234\n")
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	// A pipe is read by line, without a prompt
+	pins := newSecretReader(r)
+	assert.False(t, pins.terminal)
+
+	pin, err := pins.read("TP_LINK_CLI_TEST_PIN", "PIN")
+	require.NoError(t, err)
+	assert.Equal(t, "1234", pin)
+
+	_, err = pins.read("TP_LINK_CLI_TEST_PIN", "PIN")
+	assert.ErrorContains(t, err, "no PIN given")
+}
+
+func TestReadAPNPassword(t *testing.T) {
+	secrets := &secretReader{input: bufio.NewReader(strings.NewReader(" pass word \n"))}
+
+	// Without --password, stdin is not read
+	c := &SMSCommand{}
+	require.NoError(t, c.readAPNPassword(secrets))
+	assert.Empty(t, c.Password)
+
+	// Spaces are part of a password
+	c.ReadPassword = true
+	require.NoError(t, c.readAPNPassword(secrets))
+	assert.Equal(t, " pass word ", c.Password)
+
+	c = &SMSCommand{ReadPassword: true}
+	assert.ErrorContains(t, c.readAPNPassword(secrets), "TP_LINK_CLI_APN_PASSWORD")
+}
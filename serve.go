@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/titpetric/tp-link-cli/gateway"
+)
+
+// Serve runs the SMS gateway REST API until interrupted
+func (c *SMSCommand) Serve(ctx context.Context) error {
+	if c.Token == "" {
+		return fmt.Errorf("serve requires --token=<token> or TP_LINK_CLI_TOKEN")
+	}
+
+	listen := c.Listen
+	if listen == "" {
+		listen = ":8080"
+	}
+
+	smsClient, err := c.NewClient()
+	if err != nil {
+		return err
+	}
+
+	gw := gateway.NewServer(smsClient, c.Token)
+	server := &http.Server{
+		Addr:              listen,
+		Handler:           gw,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	if err := serveHTTP(ctx, server); err != nil {
+		return err
+	}
+	if !c.SessionCache && smsClient.TokenID != "" {
+		gw.Logout(context.Background())
+	}
+	return nil
+}
+
+// serveHTTP runs the server until ctx is done or the process is
+// interrupted, and then shuts it down gracefully.
+func serveHTTP(ctx context.Context, server *http.Server) error {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	errc := make(chan error, 1)
+	go func() {
+		errc <- server.ListenAndServe()
+	}()
+	fmt.Fprintf(os.Stderr, "Listening on %s\n", server.Addr)
+
+	select {
+	case err := <-errc:
+		return fmt.Errorf("failed to serve: %w", err)
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("failed to shut down: %w", err)
+	}
+	return nil
+}
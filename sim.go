@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// SIMStatus prints the SIM state, identity and PIN lock
+func (c *SMSCommand) SIMStatus(ctx context.Context) error {
+	smsClient, err := c.NewClient()
+	if err != nil {
+		return err
+	}
+	defer c.CloseClient(ctx, smsClient)
+
+	info, err := smsClient.SIMInfo(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get SIM status: %w", err)
+	}
+
+	rows := [][]string{
+		{"State", info.State},
+		{"IMSI", info.IMSI},
+		{"ICCID", info.ICCID},
+		{"PIN lock", strconv.FormatBool(info.PINEnabled)},
+		{"PIN attempts", strconv.Itoa(info.PINAttempts)},
+		{"PUK attempts", strconv.Itoa(info.PUKAttempts)},
+		{"PUK required", strconv.FormatBool(info.PUKRequired)},
+	}
+	return c.outputValue(os.Stdout, info, rows)
+}
+
+// SIMUnlock enters the SIM PIN, read from TP_LINK_CLI_SIM_PIN or stdin
+func (c *SMSCommand) SIMUnlock(ctx context.Context) error {
+	pins := newSecretReader(os.Stdin)
+	pin, err := pins.read("TP_LINK_CLI_SIM_PIN", "PIN")
+	if err != nil {
+		return err
+	}
+
+	smsClient, err := c.NewClient()
+	if err != nil {
+		return err
+	}
+	defer c.CloseClient(ctx, smsClient)
+
+	if err := smsClient.UnlockSIM(ctx, pin); err != nil {
+		return fmt.Errorf("failed to unlock SIM: %w", err)
+	}
+	fmt.Println("SIM unlocked")
+	return nil
+}
+
+// SIMPIN enables, disables or changes the SIM PIN. The PIN is read from
+// TP_LINK_CLI_SIM_PIN or stdin, a new PIN from TP_LINK_CLI_SIM_NEW_PIN or
+// the next line of stdin.
+func (c *SMSCommand) SIMPIN(ctx context.Context, action string) error {
+	if action != "enable" && action != "disable" && action != "change" {
+		return fmt.Errorf("unknown pin action: %s", action)
+	}
+
+	pins := newSecretReader(os.Stdin)
+	pin, err := pins.read("TP_LINK_CLI_SIM_PIN", "PIN")
+	if err != nil {
+		return err
+	}
+	var newPIN string
+	if action == "change" {
+		if newPIN, err = pins.read("TP_LINK_CLI_SIM_NEW_PIN", "New PIN"); err != nil {
+			return err
+		}
+	}
+
+	smsClient, err := c.NewClient()
+	if err != nil {
+		return err
+	}
+	defer c.CloseClient(ctx, smsClient)
+
+	switch action {
+	case "enable", "disable":
+		err = smsClient.SetSIMPINEnabled(ctx, action == "enable", pin)
+	case "change":
+		err = smsClient.ChangeSIMPIN(ctx, pin, newPIN)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to %s PIN: %w", action, err)
+	}
+	fmt.Printf("SIM PIN %sd\n", action)
+	return nil
+}
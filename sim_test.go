@@ -0,0 +1,15 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseArgsRejectsPIN(t *testing.T) {
+	_, _, err := ParseArgs([]string{"unlock", "--pin=1234"})
+	assert.ErrorContains(t, err, "visible to other users")
+
+	_, _, err = ParseArgs([]string{"unlock", "--pin"})
+	assert.NoError(t, err)
+}
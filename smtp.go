@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/titpetric/tp-link-cli/email"
+)
+
+// SMTPGateway accepts mail to <number>@sms.local and sends it as SMS
+// until interrupted.
+func (c *SMSCommand) SMTPGateway(ctx context.Context) error {
+	listen := c.Listen
+	if listen == "" {
+		listen = "127.0.0.1:2525"
+	}
+	// Without a sender allow list, the gateway would relay any mail
+	if len(c.AllowFrom) == 0 && !loopback(listen) {
+		return fmt.Errorf("smtp-gateway on %s requires --allow-from=<list>, or listen on a loopback address", listen)
+	}
+
+	smsClient, err := c.NewClient()
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	l, err := net.Listen("tcp", listen)
+	if err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "Listening on %s\n", listen)
+
+	server := &email.Server{
+		Sender:     smsClient,
+		AllowFrom:  c.AllowFrom,
+		RateLimit:  c.RateLimit,
+		RateWindow: c.RateWindow,
+		Logf: func(format string, args ...interface{}) {
+			fmt.Fprintf(os.Stderr, format+"\n", args...)
+		},
+	}
+	err = server.Serve(ctx, l)
+
+	// Log out with a fresh context, as ctx is cancelled on interrupt
+	c.CloseClient(context.Background(), smsClient)
+	return err
+}
+
+// loopback reports whether the listen address only accepts local
+// connections.
+func loopback(listen string) bool {
+	host, _, err := net.SplitHostPort(listen)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
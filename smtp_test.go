@@ -0,0 +1,28 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoopback(t *testing.T) {
+	for listen, want := range map[string]bool{
+		"127.0.0.1:2525": true,
+		"[::1]:2525":     true,
+		"localhost:2525": true,
+		":2525":          false,
+		"0.0.0.0:2525":   false,
+		"10.0.0.1:2525":  false,
+		"2525":           false,
+	} {
+		assert.Equal(t, want, loopback(listen), listen)
+	}
+}
+
+func TestSMTPGatewayOpenRelay(t *testing.T) {
+	c := &SMSCommand{Listen: ":2525"}
+	err := c.SMTPGateway(context.Background())
+	assert.ErrorContains(t, err, "requires --allow-from")
+}
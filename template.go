@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/titpetric/tp-link-cli/model"
+)
+
+func init() {
+	RegisterFormatter("template", formatTemplate)
+}
+
+// templateFuncs are the helper functions available to --template.
+var templateFuncs = template.FuncMap{
+	// date formats a time with a Go layout, e.g. {{date "2006-01-02" .RecvTime}}
+	"date": func(layout string, t time.Time) string {
+		if t.IsZero() {
+			return ""
+		}
+		return t.Format(layout)
+	},
+	// age formats a time relative to now, e.g. "2 hours ago"
+	"age": func(t time.Time) string {
+		if t.IsZero() {
+			return ""
+		}
+		return formatTime(t)
+	},
+	// unix returns the time as a unix timestamp, for comparisons in shell
+	"unix": func(t time.Time) int64 {
+		if t.IsZero() {
+			return 0
+		}
+		return t.Unix()
+	},
+	// truncate shortens a string to max characters, e.g. {{.Content | truncate 20}}
+	"truncate": func(max int, s string) string {
+		return truncate(s, max)
+	},
+	// json encodes a value as JSON, quoting and escaping strings
+	"json": func(v interface{}) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+	"contains":  func(substr, s string) bool { return strings.Contains(s, substr) },
+	"hasPrefix": func(prefix, s string) bool { return strings.HasPrefix(s, prefix) },
+	"lower":     strings.ToLower,
+	"upper":     strings.ToUpper,
+	"oneline":   func(s string) string { return strings.Join(strings.Fields(s), " ") },
+}
+
+// parseTemplate parses a --template value with the helper functions.
+func parseTemplate(text string) (*template.Template, error) {
+	tpl, err := template.New("sms").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+	return tpl, nil
+}
+
+// formatTemplate executes the template once per message. Output that does
+// not end with a newline gets one, and empty output is skipped, so a
+// template can filter messages with {{if}}.
+func formatTemplate(w io.Writer, opts FormatOptions, messages []model.SMSMessage) error {
+	if opts.Template == "" {
+		return fmt.Errorf("template format requires --template=<template>")
+	}
+
+	tpl, err := parseTemplate(opts.Template)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	for _, msg := range messages {
+		buf.Reset()
+		if err := tpl.Execute(&buf, msg); err != nil {
+			return fmt.Errorf("failed to execute template: %w", err)
+		}
+		if buf.Len() == 0 {
+			continue
+		}
+		if !bytes.HasSuffix(buf.Bytes(), []byte("\n")) {
+			buf.WriteByte('\n')
+		}
+		if _, err := w.Write(buf.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func formatWithTemplate(t *testing.T, text string) string {
+	t.Helper()
+
+	var buf bytes.Buffer
+	err := formatTemplate(&buf, FormatOptions{Folder: "inbox", Template: text}, testMessages())
+	require.NoError(t, err)
+	return buf.String()
+}
+
+func TestFormatTemplate(t *testing.T) {
+	out := formatWithTemplate(t, "{{.From}}: {{.Content | oneline}}")
+	assert.Equal(t, "+38640111222: Dobrodošli | BRZINA From now on, reply\n13909: hello\n", out)
+}
+
+func TestFormatTemplateFilter(t *testing.T) {
+	out := formatWithTemplate(t, `{{if contains "BRZINA" .Content}}{{unix .RecvTime}}{{end}}`)
+	assert.Equal(t, "1772600767\n", out)
+}
+
+func TestFormatTemplateFuncs(t *testing.T) {
+	out := formatWithTemplate(t, `{{date "2006-01-02" .RecvTime}} {{.Content | truncate 5 | json}}`)
+	assert.Equal(t, "2026-03-04 \"Dobro...\"\n2026-03-04 \"hello\"\n", out)
+
+	age := templateFuncs["age"].(func(time.Time) string)
+	assert.Equal(t, "2 hours ago", age(time.Now().Add(-2*time.Hour-time.Minute)))
+	assert.Equal(t, "", age(time.Time{}))
+}
+
+func TestFormatTemplateErrors(t *testing.T) {
+	var buf bytes.Buffer
+	err := formatTemplate(&buf, FormatOptions{}, testMessages())
+	assert.Error(t, err)
+
+	_, err = parseTemplate("{{.From")
+	assert.Error(t, err)
+
+	err = formatTemplate(&buf, FormatOptions{Template: "{{.Missing}}"}, testMessages())
+	assert.Error(t, err)
+}
+
+func TestParseArgsTemplate(t *testing.T) {
+	cmd, _, err := ParseArgs([]string{"list", "--template={{.Content}}"})
+	require.NoError(t, err)
+	assert.Equal(t, "template", cmd.Format)
+	assert.Equal(t, "{{.Content}}", cmd.Template)
+
+	_, _, err = ParseArgs([]string{"list", "--template={{.Content"})
+	assert.Error(t, err)
+}
+
+func TestTemplateUnixZero(t *testing.T) {
+	unix := templateFuncs["unix"].(func(time.Time) int64)
+	assert.Equal(t, int64(0), unix(time.Time{}))
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+)
+
+// SendUSSD sends a USSD code followed by the menu choices, and prints
+// each reply. If the session is still open after the choices, the next
+// choices are read from a terminal, an empty line ending the session.
+// Without a terminal, or on interrupt, the open session is cancelled.
+func (c *SMSCommand) SendUSSD(ctx context.Context, code string, choices []string) error {
+	smsClient, err := c.NewClient()
+	if err != nil {
+		return err
+	}
+	// Clean up with a fresh context, as ctx is cancelled on interrupt
+	defer c.CloseClient(context.Background(), smsClient)
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var input *bufio.Scanner
+	if stat, err := os.Stdin.Stat(); err == nil && stat.Mode()&os.ModeCharDevice != 0 {
+		input = bufio.NewScanner(os.Stdin)
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	request := code
+	for {
+		resp, err := smsClient.SendUSSD(ctx, request)
+		if err != nil {
+			if ctx.Err() != nil {
+				smsClient.CancelUSSD(context.Background())
+			}
+			return fmt.Errorf("failed to send USSD: %w", err)
+		}
+
+		if c.JSON || c.Format == "json" {
+			enc.Encode(resp)
+		} else {
+			fmt.Println(resp.Text)
+		}
+		if !resp.Open {
+			return nil
+		}
+
+		request = nextUSSDChoice(&choices, input)
+		if request == "" {
+			if err := smsClient.CancelUSSD(context.Background()); err != nil {
+				return fmt.Errorf("failed to cancel USSD session: %w", err)
+			}
+			return nil
+		}
+	}
+}
+
+// nextUSSDChoice returns the next menu choice from the arguments, or
+// from input if they are used up. It returns "" to end the session.
+func nextUSSDChoice(choices *[]string, input *bufio.Scanner) string {
+	if len(*choices) > 0 {
+		choice := (*choices)[0]
+		*choices = (*choices)[1:]
+		return choice
+	}
+	if input == nil {
+		return ""
+	}
+
+	fmt.Fprint(os.Stderr, "> ")
+	if !input.Scan() {
+		return ""
+	}
+	return strings.TrimSpace(input.Text())
+}
+
+// CancelUSSD ends an open USSD session
+func (c *SMSCommand) CancelUSSD(ctx context.Context) error {
+	smsClient, err := c.NewClient()
+	if err != nil {
+		return err
+	}
+	defer c.CloseClient(ctx, smsClient)
+
+	if err := smsClient.CancelUSSD(ctx); err != nil {
+		return fmt.Errorf("failed to cancel USSD session: %w", err)
+	}
+	fmt.Println("USSD session cancelled")
+	return nil
+}
+
+// positionalArgs returns the arguments which are not options
+func positionalArgs(args []string) []string {
+	var result []string
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "--") {
+			result = append(result, arg)
+		}
+	}
+	return result
+}
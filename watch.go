@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+
+	"github.com/titpetric/tp-link-cli/client"
+	"github.com/titpetric/tp-link-cli/model"
+	"github.com/titpetric/tp-link-cli/watch"
+)
+
+// WatchSMS polls the inbox and reports new messages until interrupted.
+// Each message is written as a JSON line, or passed to --exec.
+func (c *SMSCommand) WatchSMS(ctx context.Context) error {
+	enc := json.NewEncoder(os.Stdout)
+	handler := func(ctx context.Context, msg model.SMSMessage) error {
+		return enc.Encode(msg)
+	}
+	if c.Exec != "" {
+		handler = func(ctx context.Context, msg model.SMSMessage) error {
+			cmd := exec.CommandContext(ctx, "sh", "-c", c.Exec)
+			cmd.Env = append(os.Environ(), watch.Env(msg)...)
+			cmd.Stdout = os.Stdout
+			cmd.Stderr = os.Stderr
+			if err := cmd.Run(); err != nil && ctx.Err() == nil {
+				fmt.Fprintf(os.Stderr, "warning: command failed for message %d: %v\n", msg.Index, err)
+			}
+			return nil
+		}
+	}
+
+	return c.runWatch(ctx, handler)
+}
+
+// runWatch polls the inbox until interrupted, calling handler for each
+// new message.
+func (c *SMSCommand) runWatch(ctx context.Context, handler watch.Handler) error {
+	smsClient, err := c.NewClient()
+	if err != nil {
+		return err
+	}
+	return c.runWatchClient(ctx, smsClient, handler)
+}
+
+// runWatchClient is runWatch over an existing client, for handlers
+// which also use the router session. The client is closed on return.
+func (c *SMSCommand) runWatchClient(ctx context.Context, smsClient *client.SMSClient, handler watch.Handler) error {
+	// Log out with a fresh context, as ctx is cancelled on interrupt
+	defer c.CloseClient(context.Background(), smsClient)
+
+	state, err := watch.LoadState(c.State)
+	if err != nil {
+		return fmt.Errorf("failed to load state: %w", err)
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	watcher := &watch.Watcher{
+		Client:   smsClient,
+		State:    state,
+		Interval: c.Interval,
+		Backlog:  c.All,
+		Logf: func(format string, args ...interface{}) {
+			fmt.Fprintf(os.Stderr, "warning: "+format+"\n", args...)
+		},
+	}
+
+	return watcher.Run(ctx, handler)
+}
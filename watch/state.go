@@ -0,0 +1,107 @@
+package watch
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// State records the messages already reported, so a restarted watch
+// does not report them again.
+type State struct {
+	// Seen maps a message key to the time it was first reported.
+	Seen map[string]time.Time `json:"seen"`
+
+	path    string
+	loaded  bool
+	changed bool
+}
+
+// LoadState reads the state file at path. A missing file gives a new
+// state, an empty path keeps the state in memory only.
+func LoadState(path string) (*State, error) {
+	s := &State{
+		Seen: map[string]time.Time{},
+		path: path,
+	}
+	if path == "" {
+		return s, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, err
+	}
+	if s.Seen == nil {
+		s.Seen = map[string]time.Time{}
+	}
+	s.loaded = true
+	return s, nil
+}
+
+// New reports whether the state was not read from a state file.
+func (s *State) New() bool {
+	return !s.loaded
+}
+
+// Has reports whether the message key was seen.
+func (s *State) Has(key string) bool {
+	_, ok := s.Seen[key]
+	return ok
+}
+
+// Add marks the message key as seen.
+func (s *State) Add(key string) {
+	if s.Has(key) {
+		return
+	}
+	s.Seen[key] = time.Now().UTC()
+	s.changed = true
+}
+
+// Prune forgets the keys not in keep, so messages deleted from the
+// router do not accumulate in the state.
+func (s *State) Prune(keep map[string]bool) {
+	for key := range s.Seen {
+		if !keep[key] {
+			delete(s.Seen, key)
+			s.changed = true
+		}
+	}
+}
+
+// Save writes the state file if the state changed since the last save.
+func (s *State) Save() error {
+	if s.path == "" || (!s.changed && s.loaded) {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return err
+	}
+
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	s.loaded = true
+	s.changed = false
+	return nil
+}
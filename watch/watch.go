@@ -0,0 +1,183 @@
+// Package watch polls the router inbox and reports newly arrived messages.
+package watch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/titpetric/tp-link-cli/archive"
+	"github.com/titpetric/tp-link-cli/client"
+	"github.com/titpetric/tp-link-cli/model"
+)
+
+// DefaultInterval is the poll interval used when none is set.
+const DefaultInterval = 30 * time.Second
+
+// Handler is called for every new message, oldest first.
+type Handler func(ctx context.Context, msg model.SMSMessage) error
+
+// Watcher polls the inbox over a single client session.
+type Watcher struct {
+	Client   *client.SMSClient
+	State    *State
+	Interval time.Duration
+
+	// Backlog reports the messages already in the inbox when the state
+	// is new. By default they are only marked as seen.
+	Backlog bool
+
+	// Logf reports poll errors, which are retried on the next interval.
+	// If nil, poll errors stop the watch.
+	Logf func(format string, args ...interface{})
+
+	// Lock, if set, is held while polling, for clients shared with
+	// other goroutines. It is not held while calling the handler.
+	Lock sync.Locker
+}
+
+// Run polls the inbox until the context is cancelled, calling fn for
+// each new message. A message is marked as seen once fn returns, an
+// error from fn stops the watch.
+func (w *Watcher) Run(ctx context.Context, fn Handler) error {
+	interval := w.Interval
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	seed := w.State.New() && !w.Backlog
+	for {
+		err := w.poll(ctx, seed, fn)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			var herr *handlerError
+			if errors.As(err, &herr) || w.Logf == nil {
+				return err
+			}
+			w.Logf("poll failed: %v", err)
+		} else {
+			seed = false
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *Watcher) poll(ctx context.Context, seed bool, fn Handler) error {
+	if w.Lock != nil {
+		w.Lock.Lock()
+	}
+	messages, err := w.Poll(ctx)
+	if w.Lock != nil {
+		w.Lock.Unlock()
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, msg := range messages {
+		if !seed {
+			if err := fn(ctx, msg); err != nil {
+				w.State.Save()
+				return &handlerError{err}
+			}
+		}
+		w.State.Add(archive.Key(msg))
+	}
+
+	if err := w.State.Save(); err != nil {
+		return fmt.Errorf("failed to save state: %w", err)
+	}
+	return nil
+}
+
+// Poll returns the inbox messages not in the state, oldest first.
+//
+// The inbox is listed newest first, so pages are walked until a page
+// holds no new messages. If every page was walked, keys of messages no
+// longer in the inbox are pruned from the state.
+func (w *Watcher) Poll(ctx context.Context) ([]model.SMSMessage, error) {
+	box, err := w.Client.Box(ctx, "inbox")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get inbox: %w", err)
+	}
+
+	var result []model.SMSMessage
+	current := map[string]bool{}
+	complete := true
+
+	for page := 1; page <= box.Pages(); page++ {
+		resp, err := w.Client.ListPage(ctx, "inbox", page)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list inbox: %w", err)
+		}
+		if resp.Error != 0 {
+			return nil, fmt.Errorf("router returned error code: %d", resp.Error)
+		}
+		if len(resp.Data) == 0 {
+			break
+		}
+
+		added := 0
+		for _, msg := range resp.Data {
+			key := archive.Key(msg)
+			if current[key] {
+				continue
+			}
+			current[key] = true
+			if !w.State.Has(key) {
+				result = append(result, msg)
+				added++
+			}
+		}
+
+		if added == 0 && page < box.Pages() {
+			complete = false
+			break
+		}
+	}
+
+	if complete {
+		w.State.Prune(current)
+	}
+
+	archive.Sort(result)
+	return result, nil
+}
+
+// Env returns the message as SMS_* environment variables, for commands
+// run for each message.
+func Env(msg model.SMSMessage) []string {
+	return []string{
+		"SMS_INDEX=" + strconv.Itoa(msg.Index),
+		"SMS_FOLDER=" + msg.Folder,
+		"SMS_FROM=" + msg.From,
+		"SMS_TO=" + msg.To,
+		"SMS_CONTENT=" + msg.Content,
+		"SMS_TIME=" + msg.Time().Format(time.RFC3339),
+		"SMS_UNREAD=" + strconv.FormatBool(msg.Unread),
+		"SMS_HOST=" + msg.Host,
+		"SMS_HASH=" + msg.Hash,
+	}
+}
+
+// handlerError marks errors returned by the handler, which always stop
+// the watch.
+type handlerError struct {
+	err error
+}
+
+func (e *handlerError) Error() string { return e.err.Error() }
+func (e *handlerError) Unwrap() error { return e.err }
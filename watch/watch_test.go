@@ -0,0 +1,184 @@
+package watch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/titpetric/tp-link-cli/client"
+	"github.com/titpetric/tp-link-cli/fakerouter"
+	"github.com/titpetric/tp-link-cli/model"
+)
+
+var errStop = errors.New("stop")
+
+func newTestWatcher(t *testing.T, router *fakerouter.Router, statePath string) *Watcher {
+	t.Helper()
+
+	c, err := client.NewSMSClient(&client.Options{
+		Auth: "admin:secret",
+		Host: router.URL(),
+	})
+	require.NoError(t, err)
+
+	state, err := LoadState(statePath)
+	require.NoError(t, err)
+
+	return &Watcher{
+		Client:   c,
+		State:    state,
+		Interval: time.Millisecond,
+	}
+}
+
+func TestPoll(t *testing.T) {
+	router := fakerouter.New("admin", "secret")
+	defer router.Close()
+
+	now := time.Now().Add(-time.Hour)
+	for i := 0; i < 10; i++ {
+		router.Receive("+38640111222", fmt.Sprintf("message %d", i), now.Add(time.Duration(i)*time.Minute))
+	}
+
+	w := newTestWatcher(t, router, "")
+	ctx := context.Background()
+
+	messages, err := w.Poll(ctx)
+	require.NoError(t, err)
+	require.Len(t, messages, 10)
+	assert.Equal(t, "message 0", messages[0].Content)
+	assert.Equal(t, "message 9", messages[9].Content)
+
+	for _, msg := range messages {
+		w.State.Add(msg.Host + "/" + msg.Hash)
+	}
+
+	messages, err = w.Poll(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, messages)
+
+	router.Receive("+38640111222", "new", time.Now())
+	messages, err = w.Poll(ctx)
+	require.NoError(t, err)
+	require.Len(t, messages, 1)
+	assert.Equal(t, "new", messages[0].Content)
+}
+
+func TestRunSeedsNewState(t *testing.T) {
+	router := fakerouter.New("admin", "secret")
+	defer router.Close()
+
+	router.Receive("+38640111222", "old", time.Now().Add(-time.Hour))
+
+	statePath := filepath.Join(t.TempDir(), "watch.json")
+	w := newTestWatcher(t, router, statePath)
+
+	var got []string
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	err := w.Run(ctx, func(ctx context.Context, msg model.SMSMessage) error {
+		got = append(got, msg.Content)
+		return nil
+	})
+	cancel()
+	require.NoError(t, err)
+	assert.Empty(t, got)
+
+	// A restarted watch only reports messages arriving after the seed.
+	router.Receive("+38640111222", "fresh", time.Now())
+
+	w = newTestWatcher(t, router, statePath)
+	assert.False(t, w.State.New())
+
+	ctx, cancel = context.WithCancel(context.Background())
+	err = w.Run(ctx, func(ctx context.Context, msg model.SMSMessage) error {
+		got = append(got, msg.Content)
+		cancel()
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"fresh"}, got)
+
+	// And the state survives, so nothing is reported twice.
+	w = newTestWatcher(t, router, statePath)
+	messages, err := w.Poll(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, messages)
+}
+
+func TestRunBacklog(t *testing.T) {
+	router := fakerouter.New("admin", "secret")
+	defer router.Close()
+
+	router.Receive("+38640111222", "first", time.Now().Add(-2*time.Hour))
+	router.Receive("+38640111222", "second", time.Now().Add(-time.Hour))
+
+	w := newTestWatcher(t, router, "")
+	w.Backlog = true
+
+	var got []string
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	err := w.Run(ctx, func(ctx context.Context, msg model.SMSMessage) error {
+		got = append(got, msg.Content)
+		if len(got) == 2 {
+			cancel()
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"first", "second"}, got)
+}
+
+func TestRunHandlerError(t *testing.T) {
+	router := fakerouter.New("admin", "secret")
+	defer router.Close()
+
+	router.Receive("+38640111222", "first", time.Now().Add(-2*time.Hour))
+	router.Receive("+38640111222", "second", time.Now().Add(-time.Hour))
+
+	w := newTestWatcher(t, router, "")
+	w.Backlog = true
+	w.Logf = func(format string, args ...interface{}) {}
+
+	err := w.Run(context.Background(), func(ctx context.Context, msg model.SMSMessage) error {
+		if msg.Content == "second" {
+			return errStop
+		}
+		return nil
+	})
+	assert.ErrorIs(t, err, errStop)
+
+	// The failed message was not marked, so it is reported again.
+	messages, err := w.Poll(context.Background())
+	require.NoError(t, err)
+	require.Len(t, messages, 1)
+	assert.Equal(t, "second", messages[0].Content)
+}
+
+func TestEnv(t *testing.T) {
+	msg := model.SMSMessage{
+		Index:    3,
+		Folder:   "inbox",
+		From:     "13909",
+		Content:  "BRZINA",
+		RecvTime: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+		Unread:   true,
+		Host:     "192.168.1.1",
+		Hash:     "abc",
+	}
+
+	env := Env(msg)
+	assert.Contains(t, env, "SMS_INDEX=3")
+	assert.Contains(t, env, "SMS_FROM=13909")
+	assert.Contains(t, env, "SMS_CONTENT=BRZINA")
+	assert.Contains(t, env, "SMS_TIME=2025-01-02T03:04:05Z")
+	assert.Contains(t, env, "SMS_UNREAD=true")
+	assert.Contains(t, env, "SMS_HASH=abc")
+}
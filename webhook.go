@@ -0,0 +1,22 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/titpetric/tp-link-cli/webhook"
+)
+
+// WebhookSMS polls the inbox and posts each new message to --url.
+func (c *SMSCommand) WebhookSMS(ctx context.Context) error {
+	if c.URL == "" {
+		return fmt.Errorf("webhook requires --url=<url>")
+	}
+
+	sender := &webhook.Sender{
+		URL:        c.URL,
+		Secret:     c.Secret,
+		DeadLetter: c.DeadLetter,
+	}
+	return c.runWatch(ctx, sender.Handle)
+}
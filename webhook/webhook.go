@@ -0,0 +1,178 @@
+// Package webhook delivers SMS messages to an HTTP endpoint.
+//
+// Each message is POSTed as a model.SMSMessage JSON body. With a secret,
+// the body is signed with HMAC-SHA256 in the X-Signature-256 header, as
+// "sha256=<hex>". Failed deliveries are retried with exponential backoff,
+// and written to a dead-letter file when the retries are exhausted.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/titpetric/tp-link-cli/model"
+)
+
+// SignatureHeader carries the HMAC signature of the request body.
+const SignatureHeader = "X-Signature-256"
+
+// Sender defaults.
+const (
+	DefaultAttempts = 5
+	DefaultBackoff  = time.Second
+	DefaultTimeout  = 10 * time.Second
+)
+
+// Sender delivers messages to a webhook URL.
+type Sender struct {
+	URL    string
+	Secret string
+
+	// Attempts is the number of delivery attempts (default 5), with
+	// Backoff before the first retry, doubling for each retry after.
+	Attempts int
+	Backoff  time.Duration
+
+	// DeadLetter is a JSON lines file where undeliverable messages are
+	// written. If empty, a failed delivery is returned as an error.
+	DeadLetter string
+
+	// Client sends the requests, http.DefaultClient with a timeout if nil.
+	Client *http.Client
+}
+
+// DeadLetterEntry is a line of the dead-letter file.
+type DeadLetterEntry struct {
+	Time    time.Time        `json:"time"`
+	URL     string           `json:"url"`
+	Error   string           `json:"error"`
+	Message model.SMSMessage `json:"message"`
+}
+
+// Sign returns the X-Signature-256 header value for body.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether signature is valid for body, for receivers.
+func Verify(secret string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
+
+// Handle delivers the message, writing it to the dead-letter file if
+// delivery fails. It only returns an error if the message is lost.
+func (s *Sender) Handle(ctx context.Context, msg model.SMSMessage) error {
+	err := s.Deliver(ctx, msg)
+	if err == nil || ctx.Err() != nil || s.DeadLetter == "" {
+		return err
+	}
+
+	if err := s.deadLetter(msg, err); err != nil {
+		return fmt.Errorf("failed to write dead letter: %w", err)
+	}
+	return nil
+}
+
+// Deliver posts the message, retrying on network errors, 429 and 5xx
+// responses. Other 4xx responses are not retried.
+func (s *Sender) Deliver(ctx context.Context, msg model.SMSMessage) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+
+	attempts := s.Attempts
+	if attempts <= 0 {
+		attempts = DefaultAttempts
+	}
+	backoff := s.Backoff
+	if backoff <= 0 {
+		backoff = DefaultBackoff
+	}
+
+	for attempt := 1; ; attempt++ {
+		retry, err := s.post(ctx, body)
+		if err == nil {
+			return nil
+		}
+		if !retry || attempt >= attempts {
+			return fmt.Errorf("failed to deliver message %d after %d attempts: %w", msg.Index, attempt, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// post sends the body once, and reports if a failure may be retried.
+func (s *Sender) post(ctx context.Context, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "tp-link-cli")
+	if s.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign(s.Secret, body))
+	}
+
+	httpClient := s.Client
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: DefaultTimeout}
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, fmt.Errorf("webhook returned status %d", resp.StatusCode)
+}
+
+func (s *Sender) deadLetter(msg model.SMSMessage, cause error) error {
+	entry, err := json.Marshal(DeadLetterEntry{
+		Time:    time.Now().UTC(),
+		URL:     s.URL,
+		Error:   cause.Error(),
+		Message: msg,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.DeadLetter), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(s.DeadLetter, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(entry, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
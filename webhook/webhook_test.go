@@ -0,0 +1,154 @@
+package webhook
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/titpetric/tp-link-cli/client"
+	"github.com/titpetric/tp-link-cli/fakerouter"
+	"github.com/titpetric/tp-link-cli/model"
+	"github.com/titpetric/tp-link-cli/watch"
+)
+
+var testMessage = model.SMSMessage{
+	Index:    3,
+	Folder:   "inbox",
+	From:     "13909",
+	Content:  "BRZINA",
+	RecvTime: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+}
+
+func TestDeliverSigned(t *testing.T) {
+	var got model.SMSMessage
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.True(t, Verify("s3cret", body, r.Header.Get(SignatureHeader)))
+		assert.False(t, Verify("wrong", body, r.Header.Get(SignatureHeader)))
+		require.NoError(t, json.Unmarshal(body, &got))
+	}))
+	defer receiver.Close()
+
+	s := &Sender{URL: receiver.URL, Secret: "s3cret"}
+	require.NoError(t, s.Deliver(context.Background(), testMessage))
+	assert.Equal(t, testMessage, got)
+}
+
+func TestDeliverRetries(t *testing.T) {
+	var calls atomic.Int32
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer receiver.Close()
+
+	s := &Sender{URL: receiver.URL, Backoff: time.Millisecond}
+	require.NoError(t, s.Deliver(context.Background(), testMessage))
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func TestDeliverPermanentFailure(t *testing.T) {
+	var calls atomic.Int32
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer receiver.Close()
+
+	s := &Sender{URL: receiver.URL, Backoff: time.Millisecond}
+	err := s.Deliver(context.Background(), testMessage)
+	assert.ErrorContains(t, err, "status 400")
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestHandleDeadLetter(t *testing.T) {
+	var calls atomic.Int32
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer receiver.Close()
+
+	deadLetter := filepath.Join(t.TempDir(), "dead.jsonl")
+	s := &Sender{
+		URL:        receiver.URL,
+		Attempts:   3,
+		Backoff:    time.Millisecond,
+		DeadLetter: deadLetter,
+	}
+	require.NoError(t, s.Handle(context.Background(), testMessage))
+	assert.Equal(t, int32(3), calls.Load())
+
+	f, err := os.Open(deadLetter)
+	require.NoError(t, err)
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	require.True(t, scanner.Scan())
+
+	var entry DeadLetterEntry
+	require.NoError(t, json.Unmarshal(scanner.Bytes(), &entry))
+	assert.Equal(t, receiver.URL, entry.URL)
+	assert.Contains(t, entry.Error, "status 500")
+	assert.Equal(t, testMessage, entry.Message)
+	assert.False(t, scanner.Scan())
+
+	// Without a dead-letter file, the failure is returned
+	s.DeadLetter = ""
+	assert.Error(t, s.Handle(context.Background(), testMessage))
+}
+
+func TestWatchDelivery(t *testing.T) {
+	router := fakerouter.New("admin", "secret")
+	defer router.Close()
+
+	received := make(chan model.SMSMessage, 1)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg model.SMSMessage
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&msg))
+		received <- msg
+	}))
+	defer receiver.Close()
+
+	c, err := client.NewSMSClient(&client.Options{Auth: "admin:secret", Host: router.URL()})
+	require.NoError(t, err)
+	state, err := watch.LoadState("")
+	require.NoError(t, err)
+
+	router.Receive("13909", "BRZINA", time.Now())
+
+	w := &watch.Watcher{Client: c, State: state, Interval: time.Millisecond, Backlog: true}
+	s := &Sender{URL: receiver.URL}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- w.Run(ctx, s.Handle)
+	}()
+
+	select {
+	case msg := <-received:
+		assert.Equal(t, "13909", msg.From)
+		assert.Equal(t, "BRZINA", msg.Content)
+		assert.Equal(t, "inbox", msg.Folder)
+		assert.NotEmpty(t, msg.Hash)
+	case <-time.After(5 * time.Second):
+		t.Fatal("message not delivered")
+	}
+
+	cancel()
+	require.NoError(t, <-done)
+}